@@ -0,0 +1,41 @@
+package gotools
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	gostruct "github.com/kawacode/gostruct"
+	charset "golang.org/x/net/html/charset"
+)
+
+// It transcodes a response body to UTF-8 and returns it with the name of the charset
+// it was in. The charset is picked like a browser does: a BOM, the charset of the
+// Content-Type header, then a <meta charset> in the first 1024 bytes. A body with
+// none of them is taken as UTF-8 if it is valid UTF-8 and windows-1252 otherwise.
+func DecodeCharset(body []byte, contentType string) ([]byte, string, error) {
+	encoding, name, certain := charset.DetermineEncoding(body, contentType)
+	if !certain && utf8.Valid(body) {
+		name = "utf-8"
+	}
+	if name == "utf-8" {
+		return bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), name, nil
+	}
+	decoded, err := encoding.NewDecoder().Bytes(body)
+	if err != nil {
+		return body, name, fmt.Errorf("gotools: decoding %s body: %w", name, err)
+	}
+	return bytes.TrimPrefix(decoded, []byte("\xef\xbb\xbf")), name, nil
+}
+
+// It transcodes the bot's response Source to UTF-8 with DecodeCharset, using the
+// response Content-Type, and returns the charset it was in
+func DecodeResponseCharset(bot *gostruct.BotData) (string, error) {
+	contenttype, _ := headerValue(bot.HttpRequest.Response.Headers, "Content-Type")
+	decoded, name, err := DecodeCharset([]byte(bot.HttpRequest.Response.Source), contenttype)
+	if err != nil {
+		return name, err
+	}
+	bot.HttpRequest.Response.Source = string(decoded)
+	return name, nil
+}
@@ -0,0 +1,123 @@
+package gotools
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	brotli "github.com/andybalholm/brotli"
+	gostruct "github.com/kawacode/gostruct"
+)
+
+// requestBodyEncodings maps an HTTP/2 profile name to the Content-Encoding its app
+// compresses request bodies with. It starts empty, register the apps seen doing it
+// with SetRequestBodyEncoding.
+var requestBodyEncodings = make(map[string]string)
+
+var requestBodyEncodingsMu sync.RWMutex
+
+// It returns the Content-Encoding request bodies are compressed with for the profile
+func GetRequestBodyEncoding(profile string) (string, bool) {
+	requestBodyEncodingsMu.RLock()
+	defer requestBodyEncodingsMu.RUnlock()
+	encoding, exist := requestBodyEncodings[profile]
+	return encoding, exist
+}
+
+// It sets the Content-Encoding request bodies are compressed with for the profile,
+// an empty encoding turns compression off
+func SetRequestBodyEncoding(profile string, encoding string) error {
+	encoding = strings.ToLower(strings.TrimSpace(encoding))
+	if encoding != "" {
+		if _, err := CompressContent(nil, encoding); err != nil {
+			return err
+		}
+	}
+	requestBodyEncodingsMu.Lock()
+	defer requestBodyEncodingsMu.Unlock()
+	if encoding == "" {
+		delete(requestBodyEncodings, profile)
+	} else {
+		requestBodyEncodings[profile] = encoding
+	}
+	return nil
+}
+
+// It compresses data with a single content coding: gzip, deflate (zlib wrapped, as
+// browsers send it) or br. An other coding returns an *UnsupportedEncodingError.
+func CompressContent(data []byte, encoding string) ([]byte, error) {
+	var (
+		buffer bytes.Buffer
+		writer io.WriteCloser
+	)
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "gzip":
+		writer = gzip.NewWriter(&buffer)
+	case "deflate":
+		writer = zlib.NewWriter(&buffer)
+	case "br":
+		writer = brotli.NewWriter(&buffer)
+	default:
+		return nil, &UnsupportedEncodingError{Encoding: encoding}
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// It compresses the bot's request body with gzip, deflate or br and sets the
+// Content-Encoding header. A Content-Length header already set is updated, the
+// transport computes it from the body otherwise. An empty body is left alone, and
+// a body that already has a Content-Encoding returns an error.
+func CompressRequestBody(bot *gostruct.BotData, encoding string) error {
+	request := &bot.HttpRequest.Request
+	if request.Payload == "" {
+		return nil
+	}
+	if current, exist := headerValue(request.Headers, "Content-Encoding"); exist && current != "" && !strings.EqualFold(current, "identity") {
+		return fmt.Errorf("gotools: request body is already %s encoded", current)
+	}
+	compressed, err := CompressContent([]byte(request.Payload), encoding)
+	if err != nil {
+		return err
+	}
+	if request.Headers == nil {
+		request.Headers = make(map[string]string)
+	}
+	request.Payload = string(compressed)
+	setHeaderValue(request.Headers, "Content-Encoding", strings.ToLower(strings.TrimSpace(encoding)))
+	if _, exist := headerValue(request.Headers, "Content-Length"); exist {
+		setHeaderValue(request.Headers, "Content-Length", strconv.Itoa(len(compressed)))
+	}
+	return nil
+}
+
+// It compresses the bot's request body with the encoding the profile compresses
+// request bodies with, and does nothing if the profile sends them uncompressed
+func CompressRequestBodyFor(bot *gostruct.BotData, profile string) error {
+	encoding, exist := GetRequestBodyEncoding(profile)
+	if !exist {
+		return nil
+	}
+	return CompressRequestBody(bot, encoding)
+}
+
+// It sets a header, keeping the casing of the name it is already set under
+func setHeaderValue(headers map[string]string, name string, value string) {
+	for k := range headers {
+		if strings.EqualFold(strings.TrimSpace(k), name) {
+			headers[k] = value
+			return
+		}
+	}
+	headers[name] = value
+}
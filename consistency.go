@@ -0,0 +1,235 @@
+package gotools
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	gostruct "github.com/kawacode/gostruct"
+	tls "github.com/kawacode/utls"
+)
+
+// Browser engines, used to compare the parts of a fingerprint that are shared by
+// every browser built on the same engine
+const (
+	engineChromium = "chromium"
+	engineFirefox  = "firefox"
+	engineSafari   = "safari"
+	engineOkHttp   = "okhttp"
+)
+
+// pseudoHeaderOrders is the pseudo header order each engine sends
+var pseudoHeaderOrders = map[string]string{
+	":method,:authority,:scheme,:path": engineChromium,
+	":method,:path,:authority,:scheme": engineFirefox,
+	":method,:scheme,:path,:authority": engineSafari,
+}
+
+// userAgentVersions finds the major version of each browser in its User-Agent
+var userAgentVersions = map[string]*regexp.Regexp{
+	"Opera":   regexp.MustCompile(`OPR/(\d+)`),
+	"Firefox": regexp.MustCompile(`(?:Firefox|FxiOS)/(\d+)`),
+	"Chrome":  regexp.MustCompile(`(?:Chrome|CriOS)/(\d+)`),
+	"iOS":     regexp.MustCompile(`Version/(\d+)`),
+	"iPad":    regexp.MustCompile(`Version/(\d+)`),
+	"Safari":  regexp.MustCompile(`Version/(\d+)`),
+}
+
+// It returns the engine of a utls client name like "Chrome" or "iOS", or "" for
+// clients that do not mimic a browser
+func clientEngine(client string) string {
+	switch client {
+	case "Chrome", "Opera":
+		return engineChromium
+	case "Firefox":
+		return engineFirefox
+	case "Safari", "iOS", "iPad":
+		return engineSafari
+	case "Android":
+		return engineOkHttp
+	}
+	return ""
+}
+
+// It reports whether a utls client name and the browser of a User-Agent are the same
+// browser, counting Safari on macOS, iPhone and iPad as one
+func sameBrowserFamily(client string, browser string) bool {
+	return client == browser || clientEngine(client) == engineSafari && clientEngine(browser) == engineSafari
+}
+
+// It returns the browser a User-Agent claims to be, as a utls client name, and its major version
+func userAgentBrowser(useragent string) (string, string) {
+	var browser string
+	switch {
+	case strings.Contains(useragent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(useragent, "Firefox/"), strings.Contains(useragent, "FxiOS/"):
+		browser = "Firefox"
+	case strings.Contains(useragent, "Chrome/"), strings.Contains(useragent, "CriOS/"):
+		browser = "Chrome"
+	case strings.Contains(useragent, "iPhone"):
+		browser = "iOS"
+	case strings.Contains(useragent, "iPad"):
+		browser = "iPad"
+	case strings.Contains(useragent, "Safari/"):
+		browser = "Safari"
+	case strings.Contains(strings.ToLower(useragent), "okhttp"):
+		browser = "Android"
+	default:
+		return "", ""
+	}
+	if pattern, exist := userAgentVersions[browser]; exist {
+		if match := pattern.FindStringSubmatch(useragent); match != nil {
+			return browser, match[1]
+		}
+	}
+	return browser, ""
+}
+
+// It returns the engine whose header order the given order looks like, or "" if it cannot tell
+func headerOrderEngine(order []string) string {
+	position := make(map[string]int)
+	for i, key := range order {
+		key = strings.ToLower(strings.ReplaceAll(key, " ", ""))
+		if strings.HasPrefix(key, "sec-ch-ua") {
+			return engineChromium
+		}
+		if _, exist := position[key]; !exist {
+			position[key] = i
+		}
+	}
+	encoding, hasencoding := position["accept-encoding"]
+	language, haslanguage := position["accept-language"]
+	if hasencoding && haslanguage && encoding < language {
+		return engineChromium
+	}
+	useragent, hasuseragent := position["user-agent"]
+	accept, hasaccept := position["accept"]
+	if hasuseragent && hasaccept {
+		if useragent < accept {
+			return engineFirefox
+		}
+		return engineSafari
+	}
+	return ""
+}
+
+// It returns the value of a header, matching the name case-insensitively
+func headerValue(headers map[string]string, name string) (string, bool) {
+	for k, v := range headers {
+		if strings.EqualFold(strings.TrimSpace(k), name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// It reports contradictions between the parts of a configured bot's fingerprint:
+// the client against the User-Agent, the protocol against the JA3, the header and
+// pseudo header orders against the client, client hints on browsers that do not
+// send them, and headers missing from HeaderOrderKey. It returns nil if the
+// fingerprint is consistent.
+func CheckFingerprintConsistency(bot *gostruct.BotData) []string {
+	var (
+		problems []string
+		request  = &bot.HttpRequest.Request
+		client   = request.Client.Client
+		engine   = clientEngine(client)
+	)
+	useragent, hasuseragent := headerValue(request.Headers, "User-Agent")
+	if hasuseragent && engine != "" {
+		browser, version := userAgentBrowser(useragent)
+		switch {
+		case browser == "":
+		case !sameBrowserFamily(client, browser):
+			problems = append(problems, fmt.Sprintf("client %s does not match the %s User-Agent", request.Client.Str(), browser))
+		case browser == client && version != "" && request.Client.Version != "0" && strings.Split(request.Client.Version, ".")[0] != version:
+			problems = append(problems, fmt.Sprintf("client %s does not match the User-Agent version %s", request.Client.Str(), version))
+		}
+	}
+	switch request.Protocol {
+	case "1", "2":
+	default:
+		problems = append(problems, fmt.Sprintf("protocol %q is neither \"1\" nor \"2\"", request.Protocol))
+	}
+	if request.Ja3 != "" {
+		spec, err := ParseJA3(request.Ja3, request.Protocol)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("ja3 cannot be parsed: %v", err))
+		} else {
+			var hasalpn, hasalps bool
+			for _, extension := range spec.Extensions {
+				switch extension.(type) {
+				case *tls.ALPNExtension:
+					hasalpn = true
+				case *tls.ALPSExtension:
+					hasalps = true
+				}
+			}
+			if request.Protocol == "2" && !hasalpn {
+				problems = append(problems, "protocol 2 but the ja3 has no ALPN extension to negotiate h2")
+			}
+			if request.Protocol == "1" && hasalps {
+				problems = append(problems, "protocol 1 but the ja3 has an ALPS extension, which browsers only send when offering h2")
+			}
+		}
+	} else if client == tls.HelloCustom.Client {
+		problems = append(problems, "client HelloCustom needs a ja3")
+	}
+	if request.Protocol == "2" && engine != "" {
+		order := strings.ReplaceAll(strings.Join(request.HTTP2TRANSPORT.ClientProfile.PseudoHeaderOrder, ","), " ", "")
+		if pseudoengine, exist := pseudoHeaderOrders[order]; exist && pseudoengine != engine {
+			problems = append(problems, fmt.Sprintf("pseudo header order %s is a %s order but the client is %s", order, pseudoengine, request.Client.Str()))
+		}
+		if profile, exist := GetHttp2Profile(request.Client.Str()); exist && !sameHttp2Settings(profile, request.HTTP2TRANSPORT.ClientProfile) {
+			problems = append(problems, fmt.Sprintf("http2 settings differ from the %s profile", request.Client.Str()))
+		}
+	}
+	if orderengine := headerOrderEngine(request.HeaderOrderKey); orderengine != "" && engine != "" && orderengine != engine {
+		problems = append(problems, fmt.Sprintf("header order looks like a %s order but the client is %s", orderengine, request.Client.Str()))
+	}
+	var headers []string
+	for k := range request.Headers {
+		headers = append(headers, k)
+	}
+	sort.Strings(headers)
+	if engine == engineFirefox || engine == engineSafari {
+		for _, k := range headers {
+			if strings.HasPrefix(strings.ToLower(strings.TrimSpace(k)), "sec-ch-ua") {
+				problems = append(problems, fmt.Sprintf("header %s is a client hint, which %s does not send", k, client))
+			}
+		}
+	}
+	if len(request.HeaderOrderKey) > 0 {
+		ordered := make(map[string]bool)
+		for _, key := range request.HeaderOrderKey {
+			ordered[strings.ToLower(strings.ReplaceAll(key, " ", ""))] = true
+		}
+		for _, k := range headers {
+			key := strings.ToLower(strings.ReplaceAll(k, " ", ""))
+			if !ordered[key] && !strings.HasPrefix(key, "x-kc-") && !strings.HasPrefix(key, ":") {
+				problems = append(problems, fmt.Sprintf("header %s is set but missing from HeaderOrderKey", k))
+			}
+		}
+	}
+	return problems
+}
+
+// It reports whether two profiles send the same SETTINGS frame and WINDOW_UPDATE
+func sameHttp2Settings(a ClientProfile, b ClientProfile) bool {
+	if len(a.Settings) != len(b.Settings) || len(a.SettingsOrder) != len(b.SettingsOrder) || a.ConnectionFlow != b.ConnectionFlow {
+		return false
+	}
+	for id, value := range a.Settings {
+		if other, exist := b.Settings[id]; !exist || other != value {
+			return false
+		}
+	}
+	for i := range a.SettingsOrder {
+		if a.SettingsOrder[i] != b.SettingsOrder[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,402 @@
+package gotools
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gostruct "github.com/kawacode/gostruct"
+	publicsuffix "golang.org/x/net/publicsuffix"
+)
+
+// Cookie is a cookie stored in a CookieJar. A zero Expires is a session cookie, and
+// a HostOnly cookie is only sent to Domain itself, not to its subdomains.
+type Cookie struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`
+	Path     string    `json:"path"`
+	Expires  time.Time `json:"expires"`
+	Secure   bool      `json:"secure"`
+	HttpOnly bool      `json:"httpOnly"`
+	HostOnly bool      `json:"hostOnly"`
+	SameSite string    `json:"sameSite,omitempty"`
+	Created  time.Time `json:"created"`
+}
+
+// CookieJar keeps the cookies of a session across requests following RFC 6265: the
+// Domain, Path, Secure and expiry of every cookie are kept, and cookies for a
+// public suffix like "co.uk" are rejected. It is safe to use from several goroutines.
+type CookieJar struct {
+	mu      sync.Mutex
+	cookies map[string]*Cookie
+}
+
+// It returns an empty cookie jar, the zero CookieJar is empty too
+func NewCookieJar() *CookieJar {
+	return &CookieJar{cookies: make(map[string]*Cookie)}
+}
+
+// It returns the key a cookie is stored under, a cookie replaces the one with the
+// same name, domain and path
+func (c *Cookie) key() string {
+	return c.Domain + ";" + c.Path + ";" + c.Name
+}
+
+// It reports whether the cookie has expired at now
+func (c *Cookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+// It returns the host of a URL in the form cookies are matched against
+func cookieHost(u *url.URL) string {
+	host := strings.ToLower(u.Hostname())
+	return strings.TrimSuffix(host, ".")
+}
+
+// It reports whether host domain-matches domain (RFC 6265 section 5.1.3)
+func domainMatch(host string, domain string) bool {
+	if host == domain {
+		return true
+	}
+	return net.ParseIP(host) == nil && strings.HasSuffix(host, "."+domain)
+}
+
+// It returns the default path of a cookie set by a request to the URL path (RFC 6265 section 5.1.4)
+func defaultCookiePath(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return "/"
+	}
+	last := strings.LastIndex(path, "/")
+	if last == 0 {
+		return "/"
+	}
+	return path[:last]
+}
+
+// It reports whether the request path path-matches the cookie path (RFC 6265 section 5.1.4)
+func pathMatch(path string, cookiepath string) bool {
+	if path == "" {
+		path = "/"
+	}
+	if path == cookiepath {
+		return true
+	}
+	if strings.HasPrefix(path, cookiepath) {
+		return strings.HasSuffix(cookiepath, "/") || path[len(cookiepath)] == '/'
+	}
+	return false
+}
+
+// It stores the cookies of Set-Cookie header lines received from rawurl, following
+// the storage model of RFC 6265 section 5.3. Cookies with an invalid domain are
+// ignored, and a cookie whose Max-Age or Expires is in the past removes the stored one.
+func (jar *CookieJar) SetCookies(rawurl string, lines []string) error {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return fmt.Errorf("gotools: cookie jar: %w", err)
+	}
+	host := cookieHost(u)
+	if host == "" {
+		return fmt.Errorf("gotools: cookie jar: url %q has no host", rawurl)
+	}
+	now := time.Now()
+	jar.mu.Lock()
+	defer jar.mu.Unlock()
+	if jar.cookies == nil {
+		jar.cookies = make(map[string]*Cookie)
+	}
+	for _, line := range lines {
+		parsed := (&http.Response{Header: http.Header{"Set-Cookie": {line}}}).Cookies()
+		if len(parsed) == 0 {
+			continue
+		}
+		cookie, ok := newJarCookie(parsed[0], u, host, now)
+		if !ok {
+			continue
+		}
+		if old, exist := jar.cookies[cookie.key()]; exist {
+			cookie.Created = old.Created
+		}
+		if cookie.expired(now) {
+			delete(jar.cookies, cookie.key())
+			continue
+		}
+		jar.cookies[cookie.key()] = cookie
+	}
+	return nil
+}
+
+// It converts a parsed Set-Cookie line received from u into a jar cookie, and
+// returns false if the cookie must be ignored
+func newJarCookie(parsed *http.Cookie, u *url.URL, host string, now time.Time) (*Cookie, bool) {
+	cookie := &Cookie{
+		Name:     parsed.Name,
+		Value:    parsed.Value,
+		Path:     parsed.Path,
+		Secure:   parsed.Secure,
+		HttpOnly: parsed.HttpOnly,
+		Created:  now,
+	}
+	domain := strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(parsed.Domain), "."), ".")
+	if domain != "" {
+		if suffix, _ := publicsuffix.PublicSuffix(domain); suffix == domain && net.ParseIP(domain) == nil {
+			if domain != host {
+				return nil, false
+			}
+			domain = ""
+		}
+	}
+	if domain == "" {
+		cookie.Domain, cookie.HostOnly = host, true
+	} else {
+		if !domainMatch(host, domain) {
+			return nil, false
+		}
+		cookie.Domain = domain
+	}
+	if !strings.HasPrefix(cookie.Path, "/") {
+		cookie.Path = defaultCookiePath(u.EscapedPath())
+	}
+	switch {
+	case parsed.MaxAge < 0:
+		cookie.Expires = now
+	case parsed.MaxAge > 0:
+		cookie.Expires = now.Add(time.Duration(parsed.MaxAge) * time.Second)
+	case !parsed.Expires.IsZero():
+		cookie.Expires = parsed.Expires
+	}
+	switch parsed.SameSite {
+	case http.SameSiteStrictMode:
+		cookie.SameSite = "Strict"
+	case http.SameSiteLaxMode:
+		cookie.SameSite = "Lax"
+	case http.SameSiteNoneMode:
+		cookie.SameSite = "None"
+	}
+	return cookie, true
+}
+
+// It returns the cookies to send with a request to rawurl, longest path first as
+// RFC 6265 section 5.4 orders them. Secure cookies are only sent over https and wss.
+func (jar *CookieJar) Cookies(rawurl string) ([]*Cookie, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("gotools: cookie jar: %w", err)
+	}
+	host := cookieHost(u)
+	secure := u.Scheme == "https" || u.Scheme == "wss"
+	now := time.Now()
+	jar.mu.Lock()
+	defer jar.mu.Unlock()
+	var result []*Cookie
+	for key, cookie := range jar.cookies {
+		if cookie.expired(now) {
+			delete(jar.cookies, key)
+			continue
+		}
+		if cookie.HostOnly && host != cookie.Domain || !cookie.HostOnly && !domainMatch(host, cookie.Domain) {
+			continue
+		}
+		if !pathMatch(u.EscapedPath(), cookie.Path) || cookie.Secure && !secure {
+			continue
+		}
+		copied := *cookie
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i].Path) != len(result[j].Path) {
+			return len(result[i].Path) > len(result[j].Path)
+		}
+		if !result[i].Created.Equal(result[j].Created) {
+			return result[i].Created.Before(result[j].Created)
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// It returns every cookie that has not expired, sorted by domain, path and name
+func (jar *CookieJar) All() []*Cookie {
+	now := time.Now()
+	jar.mu.Lock()
+	defer jar.mu.Unlock()
+	var result []*Cookie
+	for key, cookie := range jar.cookies {
+		if cookie.expired(now) {
+			delete(jar.cookies, key)
+			continue
+		}
+		copied := *cookie
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].key() < result[j].key()
+	})
+	return result
+}
+
+// It stores a cookie as is, replacing the one with the same name, domain and path
+func (jar *CookieJar) Add(cookie Cookie) {
+	cookie.Domain = strings.TrimPrefix(strings.ToLower(cookie.Domain), ".")
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+	if cookie.Created.IsZero() {
+		cookie.Created = time.Now()
+	}
+	jar.mu.Lock()
+	defer jar.mu.Unlock()
+	if jar.cookies == nil {
+		jar.cookies = make(map[string]*Cookie)
+	}
+	jar.cookies[cookie.key()] = &cookie
+}
+
+// It stores the cookies the bot's response set for its request URL: every
+// Set-Cookie header line, and response cookies without a Set-Cookie line as host
+// only session cookies
+func (jar *CookieJar) ReadResponse(bot *gostruct.BotData) error {
+	var lines []string
+	for k, v := range bot.HttpRequest.Response.Headers {
+		if strings.EqualFold(strings.TrimSpace(k), "Set-Cookie") {
+			lines = append(lines, SplitHeaderValues(v)...)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		if parsed := (&http.Response{Header: http.Header{"Set-Cookie": {line}}}).Cookies(); len(parsed) > 0 {
+			seen[parsed[0].Name] = true
+		}
+	}
+	for name, value := range bot.HttpRequest.Response.Cookies {
+		if !seen[name] {
+			lines = append(lines, (&http.Cookie{Name: name, Value: value}).String())
+		}
+	}
+	return jar.SetCookies(bot.HttpRequest.Request.URL, lines)
+}
+
+// It replaces the bot's request cookies with the jar's cookies for its request URL,
+// so cookies of other hosts and deleted cookies are not sent. Request cookies named
+// in manual are kept and win over jar cookies of the same name.
+func (jar *CookieJar) FillBot(bot *gostruct.BotData, manual ...string) error {
+	cookies, err := jar.Cookies(bot.HttpRequest.Request.URL)
+	if err != nil {
+		return err
+	}
+	result := make(map[string]string)
+	// the most specific cookie wins when two share a name
+	for i := len(cookies) - 1; i >= 0; i-- {
+		result[cookies[i].Name] = cookies[i].Value
+	}
+	for _, name := range manual {
+		if value, exist := bot.HttpRequest.Request.Cookies[name]; exist {
+			result[name] = value
+		}
+	}
+	bot.HttpRequest.Request.Cookies = result
+	return nil
+}
+
+// It writes the cookies that have not expired in the Netscape cookies.txt format
+// used by curl, wget and browser extensions
+func (jar *CookieJar) WriteNetscape(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	writer.WriteString("# Netscape HTTP Cookie File\n\n")
+	for _, cookie := range jar.All() {
+		domain, subdomains := cookie.Domain, "FALSE"
+		if !cookie.HostOnly {
+			domain, subdomains = "."+cookie.Domain, "TRUE"
+		}
+		if cookie.HttpOnly {
+			domain = "#HttpOnly_" + domain
+		}
+		secure := "FALSE"
+		if cookie.Secure {
+			secure = "TRUE"
+		}
+		var expires int64
+		if !cookie.Expires.IsZero() {
+			expires = cookie.Expires.Unix()
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", domain, subdomains, cookie.Path, secure, expires, cookie.Name, cookie.Value)
+	}
+	return writer.Flush()
+}
+
+// It stores the cookies of a Netscape cookies.txt file, skipping expired ones. An
+// expiry of 0 is a session cookie.
+func (jar *CookieJar) ReadNetscape(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	now := time.Now()
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		httponly := strings.HasPrefix(line, "#HttpOnly_")
+		line = strings.TrimPrefix(line, "#HttpOnly_")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) == 6 {
+			fields = append(fields, "")
+		}
+		if len(fields) != 7 {
+			return fmt.Errorf("gotools: cookies.txt line %d: expected 7 tab separated fields, got %d", number, len(fields))
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return fmt.Errorf("gotools: cookies.txt line %d: invalid expiry %q", number, fields[4])
+		}
+		cookie := Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Domain:   fields[0],
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HttpOnly: httponly,
+			HostOnly: !strings.EqualFold(fields[1], "TRUE"),
+		}
+		if expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+			if cookie.expired(now) {
+				continue
+			}
+		}
+		jar.Add(cookie)
+	}
+	return scanner.Err()
+}
+
+// It encodes the cookies that have not expired as a JSON array
+func (jar *CookieJar) MarshalJSON() ([]byte, error) {
+	cookies := jar.All()
+	if cookies == nil {
+		cookies = []*Cookie{}
+	}
+	return json.Marshal(cookies)
+}
+
+// It stores the cookies of a JSON array written by MarshalJSON, skipping expired ones
+func (jar *CookieJar) UnmarshalJSON(data []byte) error {
+	var cookies []Cookie
+	if err := json.Unmarshal(data, &cookies); err != nil {
+		return fmt.Errorf("gotools: cookie jar: %w", err)
+	}
+	now := time.Now()
+	for _, cookie := range cookies {
+		if !cookie.expired(now) {
+			jar.Add(cookie)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package gotools
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	gostruct "github.com/kawacode/gostruct"
+)
+
+// It returns the names of the cookies the jar sends to rawurl, in order
+func cookieNames(t *testing.T, jar *CookieJar, rawurl string) []string {
+	t.Helper()
+	cookies, err := jar.Cookies(rawurl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, cookie := range cookies {
+		names = append(names, cookie.Name)
+	}
+	return names
+}
+
+func TestCookieJarDomains(t *testing.T) {
+	jar := NewCookieJar()
+	err := jar.SetCookies("https://www.example.co.uk/", []string{
+		"suffix=1; Domain=co.uk",
+		"parent=1; Domain=.example.co.uk",
+		"host=1",
+		"other=1; Domain=example.org",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cookieNames(t, jar, "https://www.example.co.uk/"), []string{"host", "parent"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cookies for www.example.co.uk %v, want %v", got, want)
+	}
+	if got, want := cookieNames(t, jar, "https://a.example.co.uk/"), []string{"parent"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cookies for a.example.co.uk %v, want %v", got, want)
+	}
+	if got := cookieNames(t, jar, "https://other.co.uk/"); len(got) != 0 {
+		t.Errorf("cookies for other.co.uk %v, want none", got)
+	}
+	if got := cookieNames(t, jar, "https://badexample.co.uk/"); len(got) != 0 {
+		t.Errorf("cookies for badexample.co.uk %v, want none", got)
+	}
+}
+
+func TestCookieJarPaths(t *testing.T) {
+	jar := NewCookieJar()
+	err := jar.SetCookies("https://example.com/account/login", []string{
+		"default=1",
+		"root=1; Path=/",
+		"relative=1; Path=settings",
+		"deep=1; Path=/account/settings",
+		"secure=1; Path=/; Secure",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{"https://example.com/account/settings/x", []string{"deep", "default", "relative", "root", "secure"}},
+		{"https://example.com/account", []string{"default", "relative", "root", "secure"}},
+		{"https://example.com/accounts", []string{"root", "secure"}},
+		{"http://example.com/", []string{"root"}},
+	}
+	for _, test := range tests {
+		if got := cookieNames(t, jar, test.url); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("cookies for %s %v, want %v", test.url, got, test.want)
+		}
+	}
+}
+
+func TestCookieJarExpiry(t *testing.T) {
+	jar := NewCookieJar()
+	if err := jar.SetCookies("https://example.com/", []string{"a=1", "b=2; Max-Age=3600", "c=3"}); err != nil {
+		t.Fatal(err)
+	}
+	err := jar.SetCookies("https://example.com/", []string{
+		"a=; Max-Age=0",
+		"c=; Expires=Thu, 01 Jan 1970 00:00:00 GMT",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cookieNames(t, jar, "https://example.com/"), []string{"b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cookies after deletion %v, want %v", got, want)
+	}
+	if all := jar.All(); len(all) != 1 || all[0].Expires.IsZero() {
+		t.Errorf("All() = %v, want b with an expiry", all)
+	}
+}
+
+func TestCookieJarNetscapeRoundTrip(t *testing.T) {
+	jar := NewCookieJar()
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	jar.Add(Cookie{Name: "host", Value: "1", Domain: "example.com", Path: "/", HostOnly: true, Secure: true})
+	jar.Add(Cookie{Name: "parent", Value: "a b", Domain: ".Example.com", Path: "/app", HttpOnly: true, Expires: expires})
+	var buffer bytes.Buffer
+	if err := jar.WriteNetscape(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	again := NewCookieJar()
+	if err := again.ReadNetscape(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	first, second := jar.All(), again.All()
+	if len(first) != len(second) {
+		t.Fatalf("read %d cookies, want %d", len(second), len(first))
+	}
+	for i := range first {
+		first[i].Created, second[i].Created = time.Time{}, time.Time{}
+		if !first[i].Expires.Equal(second[i].Expires) {
+			t.Errorf("%s expires %v, want %v", first[i].Name, second[i].Expires, first[i].Expires)
+		}
+		first[i].Expires, second[i].Expires = time.Time{}, time.Time{}
+		if *first[i] != *second[i] {
+			t.Errorf("read %+v, want %+v", *second[i], *first[i])
+		}
+	}
+	expired := "example.com\tFALSE\t/\tFALSE\t1\told\tx\n"
+	if err := again.ReadNetscape(bytes.NewBufferString(expired)); err != nil || len(again.All()) != 2 {
+		t.Errorf("an expired line was stored: %v %v", err, again.All())
+	}
+	if err := again.ReadNetscape(bytes.NewBufferString("example.com\tFALSE\t/\n")); err == nil {
+		t.Errorf("a short line returned no error")
+	}
+}
+
+func TestCookieJarJSONRoundTrip(t *testing.T) {
+	jar := NewCookieJar()
+	if err := jar.SetCookies("https://example.com/a/b", []string{"a=1; Max-Age=60; SameSite=Lax", "b=2; Domain=example.com; HttpOnly"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(jar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again := NewCookieJar()
+	if err := json.Unmarshal(data, again); err != nil {
+		t.Fatal(err)
+	}
+	first, second := jar.All(), again.All()
+	if len(first) != len(second) {
+		t.Fatalf("decoded %d cookies, want %d", len(second), len(first))
+	}
+	for i := range first {
+		if !first[i].Expires.Equal(second[i].Expires) || !first[i].Created.Equal(second[i].Created) {
+			t.Errorf("%s times changed: %+v, want %+v", first[i].Name, *second[i], *first[i])
+		}
+		first[i].Expires, second[i].Expires = time.Time{}, time.Time{}
+		first[i].Created, second[i].Created = time.Time{}, time.Time{}
+		if *first[i] != *second[i] {
+			t.Errorf("decoded %+v, want %+v", *second[i], *first[i])
+		}
+	}
+	if data, err := json.Marshal(NewCookieJar()); err != nil || string(data) != "[]" {
+		t.Errorf("an empty jar encodes as %s %v, want []", data, err)
+	}
+}
+
+func TestCookieJarFillBot(t *testing.T) {
+	jar := NewCookieJar()
+	var bot gostruct.BotData
+	bot.HttpRequest.Request.URL = "https://example.com/login"
+	bot.HttpRequest.Response.Headers = map[string]string{"Set-Cookie": "session=abc; Path=/"}
+	bot.HttpRequest.Response.Cookies = map[string]string{"session": "abc", "extra": "1"}
+	if err := jar.ReadResponse(&bot); err != nil {
+		t.Fatal(err)
+	}
+	if err := jar.SetCookies("https://other.com/", []string{"foreign=1"}); err != nil {
+		t.Fatal(err)
+	}
+	bot.HttpRequest.Request.Cookies = map[string]string{"stale": "1", "manual": "2", "session": "mine"}
+	if err := jar.FillBot(&bot, "manual", "session", "absent"); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"session": "mine", "extra": "1", "manual": "2"}; !reflect.DeepEqual(bot.HttpRequest.Request.Cookies, want) {
+		t.Errorf("filled cookies %v, want %v", bot.HttpRequest.Request.Cookies, want)
+	}
+	if err := jar.FillBot(&bot); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"session": "abc", "extra": "1"}; !reflect.DeepEqual(bot.HttpRequest.Request.Cookies, want) {
+		t.Errorf("filled cookies without manual %v, want %v", bot.HttpRequest.Request.Cookies, want)
+	}
+}
@@ -0,0 +1,421 @@
+package gotools
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"strconv"
+	"strings"
+
+	gostruct "github.com/kawacode/gostruct"
+	tls "github.com/kawacode/utls"
+)
+
+// curlFlags are the cURL options without an argument that ParseCurl accepts and ignores
+var curlFlags = map[string]bool{
+	"-s": true, "--silent": true, "-S": true, "--show-error": true,
+	"-v": true, "--verbose": true, "-i": true, "--include": true,
+	"-L": true, "--location": true, "-k": true, "--insecure": true,
+	"-f": true, "--fail": true, "-g": true, "--globoff": true,
+	"-N": true, "--no-buffer": true, "--path-as-is": true,
+	"--compressed": true, "-G": true, "--get": true, "-I": true, "--head": true,
+	"--http1.0": true, "--http1.1": true, "--http2": true, "--http2-prior-knowledge": true,
+}
+
+// curlIgnoredOptions are the cURL options with an argument that ParseCurl accepts and ignores
+var curlIgnoredOptions = map[string]bool{
+	"-o": true, "--output": true, "-w": true, "--write-out": true,
+	"-c": true, "--cookie-jar": true, "--connect-timeout": true,
+	"--retry": true, "-r": true, "--range": true,
+}
+
+// It splits a command line into words the way a POSIX shell does, handling single
+// quotes, double quotes, $'...' strings, backslash escapes and line continuations
+func splitShellWords(command string) ([]string, error) {
+	var (
+		words  []string
+		word   strings.Builder
+		inword bool
+		runes  = []rune(command)
+		flush  = func() {
+			if inword {
+				words = append(words, word.String())
+				word.Reset()
+				inword = false
+			}
+		}
+	)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			flush()
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				if runes[i] == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+					i++
+				}
+				if runes[i] != '\n' && runes[i] != '\r' {
+					word.WriteRune(runes[i])
+					inword = true
+				}
+			}
+		case r == '\'':
+			i++
+			for i < len(runes) && runes[i] != '\'' {
+				word.WriteRune(runes[i])
+				i++
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			inword = true
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			i += 2
+			closed := false
+			for ; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					closed = true
+					break
+				}
+				if runes[i] != '\\' || i+1 >= len(runes) {
+					word.WriteRune(runes[i])
+					continue
+				}
+				i++
+				switch runes[i] {
+				case 'n':
+					word.WriteByte('\n')
+				case 't':
+					word.WriteByte('\t')
+				case 'r':
+					word.WriteByte('\r')
+				case '0':
+					word.WriteByte(0)
+				case 'x', 'u', 'U':
+					size := map[rune]int{'x': 2, 'u': 4, 'U': 8}[runes[i]]
+					end := i + 1
+					for end < len(runes) && end-i-1 < size && strings.ContainsRune("0123456789abcdefABCDEF", runes[end]) {
+						end++
+					}
+					value, err := strconv.ParseUint(string(runes[i+1:end]), 16, 32)
+					if err != nil {
+						return nil, fmt.Errorf("invalid escape \\%c in $'...'", runes[i])
+					}
+					if runes[i] == 'x' {
+						word.WriteByte(byte(value))
+					} else {
+						word.WriteRune(rune(value))
+					}
+					i = end - 1
+				default:
+					word.WriteRune(runes[i])
+				}
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated $'...' string")
+			}
+			inword = true
+		case r == '"':
+			i++
+			closed := false
+			for ; i < len(runes); i++ {
+				if runes[i] == '"' {
+					closed = true
+					break
+				}
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			inword = true
+		default:
+			word.WriteRune(r)
+			inword = true
+		}
+	}
+	flush()
+	return words, nil
+}
+
+// It adds the name=value pairs of a Cookie header or a -b value to cookies
+func parseCookieHeader(value string, cookies map[string]string) {
+	for _, cookie := range strings.Split(value, ";") {
+		if keyvalue := strings.SplitN(strings.TrimSpace(cookie), "=", 2); len(keyvalue) == 2 {
+			cookies[keyvalue[0]] = keyvalue[1]
+		}
+	}
+}
+
+// It returns the GetHelloClient ID that matches the browser and version of a
+// User-Agent, the browser's Auto ID when the version is unknown, or nil when the
+// User-Agent is not a known browser
+func HelloClientFromUserAgent(useragent string) *tls.ClientHelloID {
+	browser, version := userAgentBrowser(useragent)
+	if browser == "" {
+		return nil
+	}
+	name := map[string]string{"iOS": "IOS", "iPad": "IPad"}[browser]
+	if name == "" {
+		name = browser
+	}
+	if browser == "Android" {
+		return GetHelloClient("HelloAndroid_11_OkHttp")
+	}
+	for _, candidate := range []string{"Hello" + name + "_" + version, "Hello" + name + "_" + version + "_0", "Hello" + name + "_Auto"} {
+		if client := GetHelloClient(candidate); client != &tls.HelloChrome_Auto || strings.EqualFold(candidate, "HelloChrome_Auto") {
+			return client
+		}
+	}
+	return &tls.HelloChrome_Auto
+}
+
+// It fills the bot from a cURL command as copied from the browser devtools ("Copy
+// as cURL (bash)"): URL, method, headers in their order, cookies, body from
+// --data, --data-raw, --data-binary, --data-urlencode or -F, proxy, timeout and
+// HTTP version. The client is picked from the User-Agent with HelloClientFromUserAgent.
+// A Cookie header goes to the cookies like -b, keeping its place in HeaderOrderKey.
+// Files referenced with @ are not read and return an error. On error the bot is
+// left unchanged.
+func ParseCurl(cmd string, bot *gostruct.BotData) error {
+	words, err := splitShellWords(cmd)
+	if err != nil {
+		return fmt.Errorf("gotools: curl: %w", err)
+	}
+	if len(words) == 0 || words[0] != "curl" {
+		return fmt.Errorf("gotools: curl: command does not start with curl")
+	}
+	var (
+		request   = &bot.HttpRequest.Request
+		rawurl    string
+		method    string
+		data      []string
+		form      [][2]string
+		headers   OrderedHeaders
+		cookies   = make(map[string]string)
+		protocol  string
+		get       bool
+		head      bool
+		compress  bool
+		proxy     string
+		proxyuser string
+		timeout   string
+		redirects string
+	)
+	for i := 1; i < len(words); i++ {
+		option, value := words[i], ""
+		if strings.HasPrefix(option, "--") && strings.Contains(option, "=") {
+			option, value = option[:strings.Index(option, "=")], option[strings.Index(option, "=")+1:]
+		} else if !strings.HasPrefix(option, "-") || option == "-" {
+			if rawurl != "" {
+				return fmt.Errorf("gotools: curl: more than one url: %q and %q", rawurl, option)
+			}
+			rawurl = option
+			continue
+		} else if !curlFlags[option] {
+			if i+1 >= len(words) {
+				return fmt.Errorf("gotools: curl: option %s needs a value", option)
+			}
+			i++
+			value = words[i]
+		}
+		switch option {
+		case "--url":
+			rawurl = value
+		case "-X", "--request":
+			method = strings.ToUpper(value)
+		case "-H", "--header":
+			colon := strings.Index(value, ":")
+			if colon == -1 {
+				if strings.HasSuffix(value, ";") {
+					headers.Add(strings.TrimSuffix(value, ";"), "")
+					continue
+				}
+				return fmt.Errorf("gotools: curl: header %q has no colon", value)
+			}
+			name := strings.TrimSpace(value[:colon])
+			if name == "" || strings.EqualFold(name, "authority") {
+				// ":authority" is copied by older Chrome versions as "authority"
+				continue
+			}
+			if strings.EqualFold(name, "Cookie") {
+				// kept in the headers for its position in the order, the bot sends it from the cookies
+				parseCookieHeader(value[colon+1:], cookies)
+			}
+			headers.Add(name, strings.TrimSpace(value[colon+1:]))
+		case "-A", "--user-agent":
+			headers.Set("User-Agent", value)
+		case "-e", "--referer":
+			headers.Set("Referer", value)
+		case "-u", "--user":
+			headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(value)))
+		case "-b", "--cookie":
+			if !strings.Contains(value, "=") {
+				return fmt.Errorf("gotools: curl: cookie files are not supported: %q", value)
+			}
+			parseCookieHeader(value, cookies)
+		case "-d", "--data", "--data-ascii", "--data-binary":
+			if strings.HasPrefix(value, "@") {
+				return fmt.Errorf("gotools: curl: reading data from files is not supported: %q", value)
+			}
+			if option != "--data-binary" {
+				value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+			}
+			data = append(data, value)
+		case "--data-raw":
+			data = append(data, value)
+		case "--data-urlencode":
+			if equal := strings.Index(value, "="); equal != -1 {
+				data = append(data, value[:equal+1]+url.QueryEscape(value[equal+1:]))
+			} else {
+				data = append(data, url.QueryEscape(value))
+			}
+		case "-F", "--form", "--form-string":
+			keyvalue := strings.SplitN(value, "=", 2)
+			if len(keyvalue) != 2 {
+				return fmt.Errorf("gotools: curl: form field %q has no =", value)
+			}
+			if option != "--form-string" && (strings.HasPrefix(keyvalue[1], "@") || strings.HasPrefix(keyvalue[1], "<")) {
+				return fmt.Errorf("gotools: curl: form files are not supported: %q", value)
+			}
+			form = append(form, [2]string{keyvalue[0], keyvalue[1]})
+		case "-x", "--proxy":
+			proxy = value
+		case "-U", "--proxy-user":
+			proxyuser = value
+		case "-m", "--max-time":
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds <= 0 {
+				return fmt.Errorf("gotools: curl: invalid max time %q", value)
+			}
+			timeout = strconv.Itoa(int(seconds + 0.999))
+		case "--max-redirs":
+			if redirects, err := strconv.Atoi(value); err != nil || redirects < -1 {
+				return fmt.Errorf("gotools: curl: invalid max redirects %q", value)
+			}
+			redirects = value
+		case "--http1.0", "--http1.1":
+			protocol = "1"
+		case "--http2", "--http2-prior-knowledge":
+			protocol = "2"
+		case "-G", "--get":
+			get = true
+		case "-I", "--head":
+			head = true
+		case "--compressed":
+			compress = true
+		default:
+			if !curlFlags[option] && !curlIgnoredOptions[option] {
+				return fmt.Errorf("gotools: curl: unsupported option %s", option)
+			}
+		}
+	}
+	if rawurl == "" {
+		return fmt.Errorf("gotools: curl: no url")
+	}
+	if !strings.Contains(rawurl, "://") {
+		rawurl = "http://" + rawurl
+	}
+	parsed, err := url.Parse(rawurl)
+	if err != nil {
+		return fmt.Errorf("gotools: curl: %w", err)
+	}
+	var body string
+	switch {
+	case len(form) > 0:
+		var buffer bytes.Buffer
+		writer := multipart.NewWriter(&buffer)
+		for _, field := range form {
+			if err := writer.WriteField(field[0], field[1]); err != nil {
+				return fmt.Errorf("gotools: curl: %w", err)
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return fmt.Errorf("gotools: curl: %w", err)
+		}
+		body = buffer.String()
+		headers.Set("Content-Type", writer.FormDataContentType())
+	case len(data) > 0 && get:
+		if parsed.RawQuery != "" {
+			parsed.RawQuery += "&"
+		}
+		parsed.RawQuery += strings.Join(data, "&")
+	case len(data) > 0:
+		body = strings.Join(data, "&")
+		if headers.Get("Content-Type") == "" {
+			headers.Add("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if method == "" {
+		switch {
+		case head:
+			method = "HEAD"
+		case body != "":
+			method = "POST"
+		default:
+			method = "GET"
+		}
+	}
+	if compress && headers.Get("Accept-Encoding") == "" {
+		headers.Add("Accept-Encoding", "deflate, gzip, br")
+	}
+	if proxyuser != "" && proxy != "" {
+		rawproxy := proxy
+		if !strings.Contains(rawproxy, "://") {
+			rawproxy = "http://" + rawproxy
+		}
+		proxyurl, err := url.Parse(rawproxy)
+		if err != nil {
+			return fmt.Errorf("gotools: curl: invalid proxy %q: %w", proxy, err)
+		}
+		if username, password, found := strings.Cut(proxyuser, ":"); found {
+			proxyurl.User = url.UserPassword(username, password)
+		} else {
+			proxyurl.User = url.User(username)
+		}
+		proxy = proxyurl.String()
+	}
+	if protocol == "" {
+		protocol = "1"
+		if parsed.Scheme == "https" {
+			protocol = "2"
+		}
+	}
+	request.URL = parsed.String()
+	request.Method = method
+	request.Payload = body
+	request.Protocol = protocol
+	if proxy != "" {
+		request.Proxy = proxy
+	}
+	if timeout != "" {
+		request.Timeout = timeout
+	}
+	if redirects != "" {
+		request.MaxRedirects = redirects
+	}
+	headers.SetToBot(bot)
+	for name := range request.Headers {
+		if strings.EqualFold(name, "Cookie") {
+			delete(request.Headers, name)
+		}
+	}
+	if len(cookies) > 0 {
+		request.Cookies = cookies
+	}
+	if client := HelloClientFromUserAgent(headers.Get("User-Agent")); client != nil {
+		request.Client = *client
+		GetHttp2SettingsfromClient(bot)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package gotools
+
+import (
+	"reflect"
+	"testing"
+
+	gostruct "github.com/kawacode/gostruct"
+	tls "github.com/kawacode/utls"
+)
+
+func TestSplitShellWords(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{`curl https://example.com`, []string{"curl", "https://example.com"}},
+		{`curl 'a b' "c d"`, []string{"curl", "a b", "c d"}},
+		{`curl -H 'x: it'\''s'`, []string{"curl", "-H", "x: it's"}},
+		{`curl "say \"hi\" \$HOME"`, []string{"curl", `say "hi" $HOME`}},
+		{`curl $'a\nb\x41é'`, []string{"curl", "a\nbAé"}},
+		{"curl \\\n  -X POST \\\r\n  url", []string{"curl", "-X", "POST", "url"}},
+		{`curl a\ b`, []string{"curl", "a b"}},
+		{`curl ''`, []string{"curl", ""}},
+	}
+	for _, test := range tests {
+		got, err := splitShellWords(test.command)
+		if err != nil {
+			t.Errorf("splitShellWords(%q): %v", test.command, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("splitShellWords(%q) = %q, want %q", test.command, got, test.want)
+		}
+	}
+	for _, command := range []string{`curl 'open`, `curl "open`, `curl $'open`} {
+		if _, err := splitShellWords(command); err == nil {
+			t.Errorf("splitShellWords(%q) returned no error", command)
+		}
+	}
+}
+
+func TestParseCurl(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		method   string
+		url      string
+		payload  string
+		headers  map[string]string
+		order    []string
+		cookies  map[string]string
+		protocol string
+	}{
+		{
+			name:     "get",
+			command:  `curl 'https://example.com/a?b=1' -H 'accept: text/html' -H 'accept-language: en'`,
+			method:   "GET",
+			url:      "https://example.com/a?b=1",
+			headers:  map[string]string{"accept": "text/html", "accept-language": "en"},
+			order:    []string{"accept", "accept-language"},
+			protocol: "2",
+		},
+		{
+			name:     "cookie header",
+			command:  `curl http://example.com -H 'accept: */*' -H 'cookie: a=1; b=2' -H 'referer: x'`,
+			method:   "GET",
+			url:      "http://example.com",
+			headers:  map[string]string{"accept": "*/*", "referer": "x"},
+			order:    []string{"accept", "cookie", "referer"},
+			cookies:  map[string]string{"a": "1", "b": "2"},
+			protocol: "1",
+		},
+		{
+			name:     "cookie option",
+			command:  `curl http://example.com -b 'a=1; b=x=y'`,
+			method:   "GET",
+			url:      "http://example.com",
+			cookies:  map[string]string{"a": "1", "b": "x=y"},
+			protocol: "1",
+		},
+		{
+			name:     "data raw",
+			command:  `curl https://example.com --data-raw '{"a":"b\n"}' -H 'content-type: application/json'`,
+			method:   "POST",
+			url:      "https://example.com",
+			payload:  `{"a":"b\n"}`,
+			headers:  map[string]string{"content-type": "application/json"},
+			order:    []string{"content-type"},
+			protocol: "2",
+		},
+		{
+			name:     "data joined and urlencoded",
+			command:  `curl https://example.com -d a=1 --data-urlencode 'b=x y' --http1.1`,
+			method:   "POST",
+			url:      "https://example.com",
+			payload:  "a=1&b=x+y",
+			headers:  map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			order:    []string{"Content-Type"},
+			protocol: "1",
+		},
+		{
+			name:     "compressed",
+			command:  `curl https://example.com --compressed -X put`,
+			method:   "PUT",
+			url:      "https://example.com",
+			headers:  map[string]string{"Accept-Encoding": "deflate, gzip, br"},
+			order:    []string{"Accept-Encoding"},
+			protocol: "2",
+		},
+		{
+			name:     "compressed keeps accept encoding",
+			command:  `curl https://example.com -H 'accept-encoding: gzip' --compressed`,
+			method:   "GET",
+			url:      "https://example.com",
+			headers:  map[string]string{"accept-encoding": "gzip"},
+			order:    []string{"accept-encoding"},
+			protocol: "2",
+		},
+	}
+	for _, test := range tests {
+		var bot gostruct.BotData
+		if err := ParseCurl(test.command, &bot); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		request := bot.HttpRequest.Request
+		if request.Method != test.method || request.URL != test.url || request.Payload != test.payload || request.Protocol != test.protocol {
+			t.Errorf("%s: got %s %s %q protocol %s, want %s %s %q protocol %s", test.name,
+				request.Method, request.URL, request.Payload, request.Protocol, test.method, test.url, test.payload, test.protocol)
+		}
+		if len(request.Headers) != len(test.headers) || len(test.headers) > 0 && !reflect.DeepEqual(request.Headers, test.headers) {
+			t.Errorf("%s: headers %v, want %v", test.name, request.Headers, test.headers)
+		}
+		if len(request.HeaderOrderKey) != len(test.order) || len(test.order) > 0 && !reflect.DeepEqual(request.HeaderOrderKey, test.order) {
+			t.Errorf("%s: header order %v, want %v", test.name, request.HeaderOrderKey, test.order)
+		}
+		if len(request.Cookies) != len(test.cookies) || len(test.cookies) > 0 && !reflect.DeepEqual(request.Cookies, test.cookies) {
+			t.Errorf("%s: cookies %v, want %v", test.name, request.Cookies, test.cookies)
+		}
+	}
+}
+
+func TestParseCurlErrorLeavesBot(t *testing.T) {
+	for _, command := range []string{
+		`curl https://example.com -x http://proxy:8080 -m 5 --max-redirs x`,
+		`curl https://example.com -x http://proxy:8080 --unknown`,
+		`curl https://example.com -m 5 -d @file`,
+		`wget https://example.com`,
+	} {
+		var bot gostruct.BotData
+		bot.HttpRequest.Request.Proxy = "http://kept:1"
+		if err := ParseCurl(command, &bot); err == nil {
+			t.Errorf("ParseCurl(%q) returned no error", command)
+		}
+		request := bot.HttpRequest.Request
+		if request.Proxy != "http://kept:1" || request.Timeout != "" || request.URL != "" {
+			t.Errorf("ParseCurl(%q) changed the bot: proxy %q timeout %q url %q", command, request.Proxy, request.Timeout, request.URL)
+		}
+	}
+}
+
+func TestHelloClientFromUserAgent(t *testing.T) {
+	tests := []struct {
+		useragent string
+		want      *tls.ClientHelloID
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/106.0.0.0 Safari/537.36", &tls.HelloChrome_106},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/99.0.0.0 Safari/537.36", &tls.HelloChrome_Auto},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:105.0) Gecko/20100101 Firefox/105.0", &tls.HelloFirefox_105},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/106.0.0.0 Safari/537.36 OPR/90.0.0.0", &tls.HelloOpera_90},
+		{"okhttp/4.9.2", &tls.HelloAndroid_11_OkHttp},
+		{"python-requests/2.28", nil},
+	}
+	for _, test := range tests {
+		if got := HelloClientFromUserAgent(test.useragent); got != test.want {
+			t.Errorf("HelloClientFromUserAgent(%q) = %v, want %v", test.useragent, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package gotools
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	brotli "github.com/andybalholm/brotli"
+	zstd "github.com/klauspost/compress/zstd"
+)
+
+// UnsupportedEncodingError is returned for a Content-Encoding that cannot be decoded
+type UnsupportedEncodingError struct {
+	Encoding string
+}
+
+func (e *UnsupportedEncodingError) Error() string {
+	return fmt.Sprintf("gotools: unsupported content encoding %q", e.Encoding)
+}
+
+// DecodeLimits bound how much a compressed body may expand when it is decoded. A
+// zero field means no limit.
+type DecodeLimits struct {
+	// MaxSize is the most decoded bytes that are read
+	MaxSize int64
+	// MaxRatio is the most decoded bytes per compressed byte. It is only checked
+	// once more than a MiB was decoded, small bodies expand a lot legitimately.
+	MaxRatio float64
+}
+
+// DefaultDecodeLimits are the limits DecodeContent uses
+var DefaultDecodeLimits = DecodeLimits{MaxSize: 64 << 20, MaxRatio: 1000}
+
+// ratioThreshold is how many bytes are decoded before MaxRatio is checked
+const ratioThreshold = 1 << 20
+
+// DecodeLimitError is returned when a decoded body exceeds its DecodeLimits
+type DecodeLimitError struct {
+	// Limit is "size" or "ratio"
+	Limit      string
+	Decoded    int64
+	Compressed int64
+	Limits     DecodeLimits
+}
+
+func (e *DecodeLimitError) Error() string {
+	if e.Limit == "ratio" {
+		return fmt.Sprintf("gotools: decoded content exceeds the ratio limit of %g (%d bytes from %d)", e.Limits.MaxRatio, e.Decoded, e.Compressed)
+	}
+	return fmt.Sprintf("gotools: decoded content exceeds the size limit of %d bytes", e.Limits.MaxSize)
+}
+
+// It splits a Content-Encoding header into its codings in the order they were applied
+func parseContentEncoding(contentEncoding string) []string {
+	var encodings []string
+	for _, encoding := range strings.Split(contentEncoding, ",") {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		if encoding != "" && encoding != "identity" {
+			encodings = append(encodings, encoding)
+		}
+	}
+	return encodings
+}
+
+// It wraps r in a reader that undoes a single content coding
+func newContentDecoder(r io.Reader, encoding string) (io.ReadCloser, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(r)
+	case "deflate":
+		// deflate is meant to be zlib wrapped, but some servers send raw deflate
+		buffered := bufio.NewReader(r)
+		header, err := buffered.Peek(2)
+		if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(buffered)
+		}
+		return flate.NewReader(buffered), nil
+	case "br":
+		return io.NopCloser(brotli.NewReader(r)), nil
+	case "zstd":
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(8<<20))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+	return nil, &UnsupportedEncodingError{Encoding: encoding}
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// contentReader is the reader returned by NewContentReader
+type contentReader struct {
+	r       io.Reader
+	source  *countingReader
+	closers []io.Closer
+	limits  DecodeLimits
+	decoded int64
+	err     error
+}
+
+func (c *contentReader) Read(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.r.Read(p)
+	c.decoded += int64(n)
+	if c.limits.MaxSize > 0 && c.decoded > c.limits.MaxSize {
+		n -= int(c.decoded - c.limits.MaxSize)
+		c.decoded = c.limits.MaxSize
+		c.err = &DecodeLimitError{Limit: "size", Decoded: c.decoded, Compressed: c.source.n, Limits: c.limits}
+		return n, c.err
+	}
+	if c.limits.MaxRatio > 0 && c.decoded > ratioThreshold && float64(c.decoded) > c.limits.MaxRatio*float64(c.source.n) {
+		c.err = &DecodeLimitError{Limit: "ratio", Decoded: c.decoded, Compressed: c.source.n, Limits: c.limits}
+		return n, c.err
+	}
+	return n, err
+}
+
+// It closes the decoders, not the underlying reader
+func (c *contentReader) Close() error {
+	for i := len(c.closers) - 1; i >= 0; i-- {
+		c.closers[i].Close()
+	}
+	return nil
+}
+
+// It returns a reader that decodes the codings of a Content-Encoding header from r
+// as it is read, without holding the body in memory. Reading past the limits
+// returns a *DecodeLimitError. Close releases the decoders.
+func NewContentReader(r io.Reader, contentEncoding string, limits DecodeLimits) (io.ReadCloser, error) {
+	source := &countingReader{r: r}
+	content := &contentReader{r: source, source: source, limits: limits}
+	encodings := parseContentEncoding(contentEncoding)
+	for i := len(encodings) - 1; i >= 0; i-- {
+		decoder, err := newContentDecoder(content.r, encodings[i])
+		if err != nil {
+			content.Close()
+			return nil, err
+		}
+		content.r = decoder
+		content.closers = append(content.closers, decoder)
+	}
+	return content, nil
+}
+
+// It undoes the codings of a Content-Encoding header like "gzip" or "gzip, br",
+// last applied first, within DefaultDecodeLimits. gzip, deflate (zlib or raw), br
+// and zstd are supported, an other coding returns an *UnsupportedEncodingError.
+// body is never modified, and on error it is returned as is next to the error.
+func DecodeContent(body []byte, contentEncoding string) ([]byte, error) {
+	return DecodeContentLimited(body, contentEncoding, DefaultDecodeLimits)
+}
+
+// It is DecodeContent with the given limits
+func DecodeContentLimited(body []byte, contentEncoding string, limits DecodeLimits) ([]byte, error) {
+	reader, err := NewContentReader(bytes.NewReader(body), contentEncoding, limits)
+	if err != nil {
+		return body, err
+	}
+	defer reader.Close()
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		var limit *DecodeLimitError
+		if errors.As(err, &limit) {
+			return body, err
+		}
+		return body, fmt.Errorf("gotools: decoding %s content: %w", contentEncoding, err)
+	}
+	return decoded, nil
+}
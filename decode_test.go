@@ -0,0 +1,98 @@
+package gotools
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"testing"
+
+	brotli "github.com/andybalholm/brotli"
+)
+
+func gzipped(t *testing.T, data []byte) []byte {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func brotlied(t *testing.T, data []byte) []byte {
+	var buffer bytes.Buffer
+	writer := brotli.NewWriter(&buffer)
+	if _, err := writer.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestDecodeContentChained(t *testing.T) {
+	body := []byte("hello, chained content codings")
+	// "gzip, br" means gzip was applied first, so br is undone first
+	encoded := brotlied(t, gzipped(t, body))
+	decoded, err := DecodeContent(encoded, "gzip, br")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, body) {
+		t.Errorf("decoded %q, want %q", decoded, body)
+	}
+	if decoded, err := DecodeContent(body, "identity"); err != nil || !bytes.Equal(decoded, body) {
+		t.Errorf("identity decoded %q, %v, want the body", decoded, err)
+	}
+}
+
+func TestDecodeContentUnsupported(t *testing.T) {
+	body := []byte("data")
+	decoded, err := DecodeContent(body, "gzip, compress")
+	var unsupported *UnsupportedEncodingError
+	if !errors.As(err, &unsupported) || unsupported.Encoding != "compress" {
+		t.Fatalf("got %v, want an *UnsupportedEncodingError for compress", err)
+	}
+	if !bytes.Equal(decoded, body) {
+		t.Errorf("got %q next to the error, want the body", decoded)
+	}
+}
+
+func TestDecodeContentLimits(t *testing.T) {
+	bomb := gzipped(t, make([]byte, 8<<20))
+	tests := []struct {
+		name   string
+		limits DecodeLimits
+		limit  string
+	}{
+		{"size", DecodeLimits{MaxSize: 1 << 20}, "size"},
+		{"ratio", DecodeLimits{MaxRatio: 100}, "ratio"},
+	}
+	for _, test := range tests {
+		decoded, err := DecodeContentLimited(bomb, "gzip", test.limits)
+		var limit *DecodeLimitError
+		if !errors.As(err, &limit) || limit.Limit != test.limit {
+			t.Errorf("%s: got %v, want a %s *DecodeLimitError", test.name, err, test.limit)
+			continue
+		}
+		if !bytes.Equal(decoded, bomb) {
+			t.Errorf("%s: got %d bytes next to the error, want the body", test.name, len(decoded))
+		}
+	}
+	decoded, err := DecodeContentLimited(bomb, "gzip", DecodeLimits{})
+	if err != nil || len(decoded) != 8<<20 {
+		t.Errorf("without limits got %d bytes, %v, want %d bytes", len(decoded), err, 8<<20)
+	}
+}
+
+func TestDecompressGzip(t *testing.T) {
+	if got, err := DecompressGzip("plain text"); got != "plain text" || err != nil {
+		t.Errorf("DecompressGzip(plain text) = %q, %v, want the input and no error", got, err)
+	}
+	if got, err := DecompressGzip(string(gzipped(t, []byte("hi")))); got != "hi" || err != nil {
+		t.Errorf("DecompressGzip(gzip) = %q, %v, want \"hi\"", got, err)
+	}
+}
@@ -0,0 +1,127 @@
+package gotools
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// DefaultMaxDelay is the upper bound of a delay whose Max is 0, so a long tail
+// cannot block for hours
+const DefaultMaxDelay = time.Minute
+
+// Delay is a distribution of waiting times between requests
+type Delay interface {
+	// It returns a waiting time drawn from r
+	Duration(r *Rand) time.Duration
+}
+
+// UniformDelay waits between Min and Max, every duration as likely
+type UniformDelay struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// NormalDelay waits around Mean, most waits within StdDev of it, kept between Min and
+// Max. A Max of 0 means DefaultMaxDelay.
+type NormalDelay struct {
+	Mean   time.Duration
+	StdDev time.Duration
+	Min    time.Duration
+	Max    time.Duration
+}
+
+// LogNormalDelay waits around Median with a long tail of slow waits, like a person
+// reading a page. Sigma sets how long the tail is, 0.5 is a reasonable start. It is
+// kept between Min and Max, a Max of 0 means DefaultMaxDelay.
+type LogNormalDelay struct {
+	Median time.Duration
+	Sigma  float64
+	Min    time.Duration
+	Max    time.Duration
+}
+
+// ExponentialDelay waits Mean on average, short waits being the most likely, like
+// the time between independent events. It is kept between Min and Max, a Max of 0
+// means DefaultMaxDelay.
+type ExponentialDelay struct {
+	Mean time.Duration
+	Min  time.Duration
+	Max  time.Duration
+}
+
+// ParetoDelay waits at least Min with a heavy tail of long "think time" pauses. The
+// lower Alpha, the heavier the tail, 1.5 to 3 is typical. It is kept below Max, a Max
+// of 0 means DefaultMaxDelay.
+type ParetoDelay struct {
+	Min   time.Duration
+	Alpha float64
+	Max   time.Duration
+}
+
+// It returns the duration for a value in nanoseconds kept between min and max, a
+// max of 0 or less meaning DefaultMaxDelay
+func boundDelay(nanoseconds float64, min time.Duration, max time.Duration) time.Duration {
+	if min < 0 {
+		min = 0
+	}
+	if max <= 0 {
+		max = DefaultMaxDelay
+	}
+	if max < min {
+		max = min
+	}
+	switch {
+	case math.IsNaN(nanoseconds) || nanoseconds < float64(min):
+		return min
+	case nanoseconds > float64(max):
+		return max
+	}
+	return time.Duration(nanoseconds)
+}
+
+func (d UniformDelay) Duration(r *Rand) time.Duration {
+	if d.Max <= d.Min {
+		return boundDelay(float64(d.Min), d.Min, d.Min)
+	}
+	return boundDelay(float64(d.Min)+r.Float()*float64(d.Max-d.Min), d.Min, d.Max)
+}
+
+func (d NormalDelay) Duration(r *Rand) time.Duration {
+	return boundDelay(float64(d.Mean)+r.NormFloat()*float64(d.StdDev), d.Min, d.Max)
+}
+
+func (d LogNormalDelay) Duration(r *Rand) time.Duration {
+	return boundDelay(float64(d.Median)*math.Exp(r.NormFloat()*d.Sigma), d.Min, d.Max)
+}
+
+func (d ExponentialDelay) Duration(r *Rand) time.Duration {
+	return boundDelay(r.ExpFloat()*float64(d.Mean), d.Min, d.Max)
+}
+
+func (d ParetoDelay) Duration(r *Rand) time.Duration {
+	if d.Alpha <= 0 {
+		return boundDelay(float64(d.Min), d.Min, d.Max)
+	}
+	// 1-Float is in (0, 1], so the power is finite
+	return boundDelay(float64(d.Min)/math.Pow(1-r.Float(), 1/d.Alpha), d.Min, d.Max)
+}
+
+// It waits for a duration drawn from delay with r, and returns ctx.Err() early if
+// the context is done first
+func (r *Rand) Sleep(ctx context.Context, delay Delay) error {
+	timer := time.NewTimer(delay.Duration(r))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// It waits for a duration drawn from delay with DefaultRand, and returns ctx.Err()
+// early if the context is done first
+func Sleep(ctx context.Context, delay Delay) error {
+	return DefaultRand.Sleep(ctx, delay)
+}
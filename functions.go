@@ -0,0 +1,208 @@
+package gotools
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"html"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Function transforms an input, key is only used by keyed functions like HMACs
+type Function func(input string, key string) (string, error)
+
+// UnknownFunctionError is returned by ApplyFunction for a name no function is registered under
+type UnknownFunctionError struct {
+	Name string
+}
+
+func (e *UnknownFunctionError) Error() string {
+	return fmt.Sprintf("gotools: unknown function %q", e.Name)
+}
+
+// hashes are the hash functions registered as "<name>", "<name>-base64",
+// "hmac-<name>" and "hmac-<name>-base64"
+var hashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// functions maps a lower case name to its function
+var functions = map[string]Function{
+	"base64-encode":    encodeFunction(base64.StdEncoding.EncodeToString),
+	"base64-decode":    decodeFunction(base64.StdEncoding.DecodeString),
+	"base64url-encode": encodeFunction(base64.URLEncoding.EncodeToString),
+	"base64url-decode": decodeFunction(base64.URLEncoding.DecodeString),
+	"base64raw-encode": encodeFunction(base64.RawStdEncoding.EncodeToString),
+	"base64raw-decode": decodeFunction(base64.RawStdEncoding.DecodeString),
+	"hex-encode":       encodeFunction(hex.EncodeToString),
+	"hex-decode":       decodeFunction(hex.DecodeString),
+	"url-encode": func(input string, key string) (string, error) {
+		return url.QueryEscape(input), nil
+	},
+	"url-decode": func(input string, key string) (string, error) {
+		return url.QueryUnescape(input)
+	},
+	"html-encode": func(input string, key string) (string, error) {
+		return html.EscapeString(input), nil
+	},
+	"html-decode": func(input string, key string) (string, error) {
+		return html.UnescapeString(input), nil
+	},
+	"unicode-escape": func(input string, key string) (string, error) {
+		return UnicodeEscape(input), nil
+	},
+	"unicode-unescape": func(input string, key string) (string, error) {
+		return UnicodeUnescape(input)
+	},
+	"crc32": func(input string, key string) (string, error) {
+		return fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(input))), nil
+	},
+}
+
+var functionsMu sync.RWMutex
+
+func init() {
+	for name, newhash := range hashes {
+		newhash := newhash
+		functions[name] = hashFunction(newhash, false, hex.EncodeToString)
+		functions[name+"-base64"] = hashFunction(newhash, false, base64.StdEncoding.EncodeToString)
+		functions["hmac-"+name] = hashFunction(newhash, true, hex.EncodeToString)
+		functions["hmac-"+name+"-base64"] = hashFunction(newhash, true, base64.StdEncoding.EncodeToString)
+	}
+}
+
+// It returns a function encoding the input bytes
+func encodeFunction(encode func([]byte) string) Function {
+	return func(input string, key string) (string, error) {
+		return encode([]byte(input)), nil
+	}
+}
+
+// It returns a function decoding the input to bytes
+func decodeFunction(decode func(string) ([]byte, error)) Function {
+	return func(input string, key string) (string, error) {
+		decoded, err := decode(input)
+		return string(decoded), err
+	}
+}
+
+// It returns a function hashing the input, with the key as HMAC key if keyed is set
+func hashFunction(newhash func() hash.Hash, keyed bool, encode func([]byte) string) Function {
+	return func(input string, key string) (string, error) {
+		h := newhash()
+		if keyed {
+			h = hmac.New(newhash, []byte(key))
+		}
+		h.Write([]byte(input))
+		return encode(h.Sum(nil)), nil
+	}
+}
+
+// It applies the function registered under name to the input, like
+// ApplyFunction("hmac-sha256", payload, secret) or ApplyFunction("base64-encode", s, "").
+// Names are case-insensitive, FunctionNames lists them. Hashes and HMACs return
+// lower case hex, or base64 for the names ending in "-base64".
+func ApplyFunction(name string, input string, key string) (string, error) {
+	functionsMu.RLock()
+	function, exist := functions[strings.ToLower(strings.TrimSpace(name))]
+	functionsMu.RUnlock()
+	if !exist {
+		return "", &UnknownFunctionError{Name: name}
+	}
+	result, err := function(input, key)
+	if err != nil {
+		return "", fmt.Errorf("gotools: function %s: %w", name, err)
+	}
+	return result, nil
+}
+
+// It registers a function under name for ApplyFunction, replacing any function
+// already registered under that name
+func RegisterFunction(name string, function Function) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || function == nil {
+		return fmt.Errorf("gotools: function needs a name and a func")
+	}
+	functionsMu.Lock()
+	defer functionsMu.Unlock()
+	functions[name] = function
+	return nil
+}
+
+// It returns the names of the registered functions in sorted order
+func FunctionNames() []string {
+	functionsMu.RLock()
+	defer functionsMu.RUnlock()
+	var names []string
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// It replaces every non ASCII character with a \uXXXX escape, characters outside
+// the Basic Multilingual Plane with a surrogate pair, as JavaScript and JSON do
+func UnicodeEscape(s string) string {
+	var result strings.Builder
+	for _, r := range s {
+		switch {
+		case r < 0x80:
+			result.WriteRune(r)
+		case r > 0xffff:
+			high, low := utf16.EncodeRune(r)
+			fmt.Fprintf(&result, "\\u%04x\\u%04x", high, low)
+		default:
+			fmt.Fprintf(&result, "\\u%04x", r)
+		}
+	}
+	return result.String()
+}
+
+// It replaces every \uXXXX escape with its character, joining surrogate pairs. Other
+// backslashes are kept.
+func UnicodeUnescape(s string) (string, error) {
+	var result strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) || s[i+1] != 'u' {
+			result.WriteByte(s[i])
+			continue
+		}
+		if i+6 > len(s) {
+			return "", fmt.Errorf("gotools: truncated unicode escape %q", s[i:])
+		}
+		code, err := strconv.ParseUint(s[i+2:i+6], 16, 16)
+		if err != nil {
+			return "", fmt.Errorf("gotools: invalid unicode escape %q", s[i:i+6])
+		}
+		r := rune(code)
+		i += 5
+		if utf16.IsSurrogate(r) && i+6 < len(s) && s[i+1] == '\\' && s[i+2] == 'u' {
+			if low, err := strconv.ParseUint(s[i+3:i+7], 16, 16); err == nil {
+				if pair := utf16.DecodeRune(r, rune(low)); pair != unicode.ReplacementChar {
+					r = pair
+					i += 6
+				}
+			}
+		}
+		result.WriteRune(r)
+	}
+	return result.String(), nil
+}
@@ -3,20 +3,22 @@ module github.com/kawacode/gotools
 go 1.18
 
 require (
+	github.com/andybalholm/brotli v1.0.4
+	github.com/andybalholm/cascadia v1.3.1
 	github.com/gofiber/fiber/v2 v2.37.0
 	github.com/kawacode/fhttp v0.4.5
 	github.com/kawacode/gostruct v1.0.5
 	github.com/kawacode/utls v1.1.7
+	github.com/klauspost/compress v1.15.0
+	golang.org/x/net v0.0.0-20220420153159-1850ba15e1be
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/andybalholm/brotli v1.0.4 // indirect
-	github.com/klauspost/compress v1.15.0 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasthttp v1.39.0 // indirect
 	github.com/valyala/tcplisten v1.0.0 // indirect
 	golang.org/x/crypto v0.0.0-20220411220226-7b82a4e95df4 // indirect
-	golang.org/x/net v0.0.0-20220420153159-1850ba15e1be // indirect
 	golang.org/x/sys v0.0.0-20220227234510-4e6760a101f9 // indirect
 	golang.org/x/text v0.3.7 // indirect
 )
@@ -2,18 +2,17 @@ package gotools
 
 import (
 	"bytes"
-	"compress/gzip"
 	"fmt"
 	"io/ioutil"
-	"math"
-	"math/rand"
+	"net"
+	"net/url"
 	"strings"
 	"time"
 
 	fiber "github.com/gofiber/fiber/v2"
-	http2 "github.com/kawacode/fhttp/http2"
 	gostruct "github.com/kawacode/gostruct"
 	tls "github.com/kawacode/utls"
+	idna "golang.org/x/net/idna"
 )
 
 // It takes a JA3 string and returns a tls.ClientHelloSpec
@@ -23,44 +22,38 @@ func ParseJA3(Ja3 string, Protocol string) (*tls.ClientHelloSpec, error) {
 		tlsinfo    tls.ClientHelloInfo
 		extensions string
 	)
-	for i, v := range strings.SplitN(Ja3, ",", 5) {
+	for i, v := range strings.SplitN(strings.TrimSpace(Ja3), ",", 5) {
+		v = strings.TrimSpace(v)
 		switch i {
 		case 0:
-			_, err := fmt.Sscan(v, &tlsspec.TLSVersMax)
+			version, err := ParseUint(v, 16)
 			if err != nil {
 				return nil, err
 			}
+			tlsspec.TLSVersMax = uint16(version)
 		case 1:
-			tlsspec.CipherSuites = append(tlsspec.CipherSuites, tls.GREASE_PLACEHOLDER)
-			for _, chiperkey := range strings.Split(v, "-") {
-				var cipher uint16
-				_, err := fmt.Sscan(chiperkey, &cipher)
-				if err != nil {
-					return nil, err
-				}
-				tlsspec.CipherSuites = append(tlsspec.CipherSuites, cipher)
+			ciphers, err := ParseUint16List(v, "-")
+			if err != nil {
+				return nil, err
 			}
+			tlsspec.CipherSuites = append([]uint16{tls.GREASE_PLACEHOLDER}, ciphers...)
 		case 2:
 			extensions = v
 		case 3:
+			curves, err := ParseUint16List(v, "-")
+			if err != nil {
+				return nil, err
+			}
 			tlsinfo.SupportedCurves = append(tlsinfo.SupportedCurves, tls.GREASE_PLACEHOLDER)
-			for _, curveid := range strings.Split(v, "-") {
-				var curves tls.CurveID
-				_, err := fmt.Sscan(curveid, &curves)
-				if err != nil {
-					return nil, err
-				}
-				tlsinfo.SupportedCurves = append(tlsinfo.SupportedCurves, curves)
+			for _, curve := range curves {
+				tlsinfo.SupportedCurves = append(tlsinfo.SupportedCurves, tls.CurveID(curve))
 			}
 		case 4:
-			for _, point := range strings.Split(v, "-") {
-				var points uint8
-				_, err := fmt.Sscan(point, &points)
-				if err != nil {
-					return nil, err
-				}
-				tlsinfo.SupportedPoints = append(tlsinfo.SupportedPoints, points)
+			points, err := ParseUint8List(v, "-")
+			if err != nil {
+				return nil, err
 			}
+			tlsinfo.SupportedPoints = points
 		}
 	}
 	tlsspec.Extensions = append(tlsspec.Extensions, &tls.UtlsGREASEExtension{})
@@ -162,12 +155,11 @@ func ParseJA3(Ja3 string, Protocol string) (*tls.ClientHelloSpec, error) {
 		case "44":
 			tlsext = &tls.CookieExtension{}
 		default:
-			var id uint16
-			_, err := fmt.Sscan(extenionsvalue, &id)
+			id, err := ParseUint(extenionsvalue, 16)
 			if err != nil {
 				return nil, err
 			}
-			tlsext = &tls.GenericExtension{Id: id}
+			tlsext = &tls.GenericExtension{Id: uint16(id)}
 		}
 		tlsspec.Extensions = append(tlsspec.Extensions, tlsext)
 	}
@@ -267,11 +259,15 @@ func GetHelloClient(client string) *tls.ClientHelloID {
 	case strings.ToUpper("HelloRandomizedNoALPN"):
 		return &tls.HelloRandomizedNoALPN
 	default:
+		if id, exist := getLoadedHelloClient(client); exist {
+			return id
+		}
 		return &tls.HelloChrome_Auto
 	}
 }
 
 // Convert a map of string slices to a map of strings.
+// Only the last value of each key is kept, use MapStringSliceToMapStringLossless to keep them all.
 func MapStringSliceToMapString(headers map[string][]string) map[string]string {
 	var result = make(map[string]string)
 	for key, value := range headers {
@@ -282,32 +278,143 @@ func MapStringSliceToMapString(headers map[string][]string) map[string]string {
 	return result
 }
 
+// Convert a map of string slices to a map of strings without dropping values.
+// Repeated headers are joined with ", " as RFC 9110 allows for list fields, except
+// Set-Cookie, whose lines cannot be combined and are joined with "\n" instead.
+// SplitHeaderValues splits a joined value back into its lines.
+func MapStringSliceToMapStringLossless(headers map[string][]string) map[string]string {
+	var result = make(map[string]string)
+	for key, value := range headers {
+		if len(value) > 0 {
+			result[key] = joinHeaderValues(key, value)
+		}
+	}
+	return result
+}
+
 // It takes a map of strings and returns a map of strings with removed kawacode headers
+// and the Host header set to the host of the bot's URL. The Host header is kept as
+// sent if the URL has no usable host, RemoveKawaCodeHeadersChecked reports that as
+// an error instead.
+// Read them with ParseKawaCodeHeaders first to configure the bot.
 func RemoveKawaCodeHeaders(headers map[string]string, bot *gostruct.BotData) map[string]string {
+	returnheaders, err := RemoveKawaCodeHeadersChecked(headers, bot)
+	if err != nil {
+		returnheaders = make(map[string]string)
+		for k, v := range headers {
+			if !strings.Contains(strings.ToLower(k), "x-kc-") {
+				returnheaders[k] = v
+			}
+		}
+	}
+	return returnheaders
+}
+
+// It is RemoveKawaCodeHeaders returning an error when the headers have a Host header
+// and no host can be derived from the bot's URL
+func RemoveKawaCodeHeadersChecked(headers map[string]string, bot *gostruct.BotData) (map[string]string, error) {
 	returnheaders := make(map[string]string)
 	for k, v := range headers {
-		if strings.Contains(strings.ToLower(k), "host") {
-			returnheaders[k] = strings.Split(bot.HttpRequest.Request.URL, "/")[2]
+		if strings.EqualFold(strings.TrimSpace(k), "host") {
+			host, err := HostFromURL(bot.HttpRequest.Request.URL)
+			if err != nil {
+				return nil, err
+			}
+			returnheaders[k] = host
 		} else if !strings.Contains(strings.ToLower(k), "x-kc-") {
 			returnheaders[k] = v
 		}
 	}
-	return returnheaders
+	return returnheaders, nil
 }
 
-// > Converts a map of strings to a map of string slices
+// hostProfile punycodes IDN hosts like idna.Lookup, but without the STD3 rules that
+// reject the "_" browsers accept in host names
+var hostProfile = idna.New(idna.MapForLookup(), idna.StrictDomainName(false), idna.BidiRule())
+
+// It returns the Host header value for a URL: the lowercased host without
+// credentials, with IDN hosts punycode encoded, IPv6 literals in brackets, and the
+// port unless it is the default port of the scheme. URLs without a scheme are read
+// as http.
+func HostFromURL(rawurl string) (string, error) {
+	rawurl = strings.TrimSpace(rawurl)
+	if !strings.Contains(rawurl, "://") {
+		rawurl = "http://" + strings.TrimPrefix(rawurl, "//")
+	}
+	parsed, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+	hostname := parsed.Hostname()
+	if hostname == "" {
+		return "", fmt.Errorf("gotools: url %q has no host", rawurl)
+	}
+	if !strings.Contains(hostname, ":") && !isASCII(hostname) {
+		hostname, err = hostProfile.ToASCII(hostname)
+		if err != nil {
+			return "", fmt.Errorf("gotools: url %q has an invalid host: %w", rawurl, err)
+		}
+	}
+	hostname = strings.ToLower(hostname)
+	port := parsed.Port()
+	switch {
+	case port == "80" && (parsed.Scheme == "http" || parsed.Scheme == "ws"),
+		port == "443" && (parsed.Scheme == "https" || parsed.Scheme == "wss"):
+		port = ""
+	}
+	if port != "" {
+		return net.JoinHostPort(hostname, port), nil
+	}
+	if strings.Contains(hostname, ":") {
+		return "[" + hostname + "]", nil
+	}
+	return hostname, nil
+}
+
+// It reports whether the string has only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// > Converts a map of strings to a map of string slices, with the header order of
+// HeaderOrderKey merged into the default header order of the bot's browser.
+// Content-Length is dropped, and for HTTP/2 the connection-specific headers are
+// dropped and names lowercased. Use FilterHeaders first for forwarded headers.
 func MapStringToMapStringSlice(MapString map[string]string, bot *gostruct.BotData) map[string][]string {
 	var result = make(map[string][]string)
+	var connection []string
 	for key, value := range MapString {
-		if strings.Contains(key, "Content-Length") {
-		} else {
-			result[key] = []string{value}
+		if strings.EqualFold(strings.TrimSpace(key), "Connection") {
+			connection = append(connection, value)
+		}
+	}
+	filter := newSendFilter(connection, bot.HttpRequest.Request.Protocol)
+	for key, value := range MapString {
+		if filter.keep(key, value) {
+			result[filter.name(key)] = []string{value}
 		}
 	}
-	if len(bot.HttpRequest.Request.HeaderOrderKey) > 1 {
-		var HeaderOrderKey []string
-		for _, v := range bot.HttpRequest.Request.HeaderOrderKey {
-			HeaderOrderKey = append(HeaderOrderKey, strings.ReplaceAll(v, " ", ""))
+	var headers []string
+	for key := range result {
+		headers = append(headers, key)
+	}
+	setHeaderOrderKeys(result, bot.HttpRequest.Request.HeaderOrderKey, headers, bot)
+	return result
+}
+
+// It adds the "Header-Order:" key, the user order merged into the default header
+// order of the bot's browser, and for HTTP/2 the "PHeader-Order:" key
+func setHeaderOrderKeys(result map[string][]string, userorder []string, headers []string, bot *gostruct.BotData) {
+	if HeaderOrderKey := MergeHeaderOrder(userorder, GetDefaultHeaderOrder(bot), headers); len(HeaderOrderKey) > 1 {
+		if bot.HttpRequest.Request.Protocol == "2" {
+			for i, v := range HeaderOrderKey {
+				HeaderOrderKey[i] = strings.ToLower(v)
+			}
 		}
 		result["Header-Order:"] = HeaderOrderKey
 	}
@@ -327,19 +434,26 @@ func MapStringToMapStringSlice(MapString map[string]string, bot *gostruct.BotDat
 			}
 		}
 	}
-	return result
 }
 
-// It takes two integers, min and max, and returns a random integer between min and max
+// It takes two integers, min and max, and returns a random integer between min and max,
+// max excluded. It returns min if max is not greater than min. Use DefaultRand.IntRange
+// to include max or to get an error for an invalid range.
 func RandomInt(min int, max int) int {
-	rand.Seed(time.Now().UnixNano())
-	return (min + rand.Intn(max-min))
+	result, err := DefaultRand.IntRangeExclusive(min, max)
+	if err != nil {
+		return min
+	}
+	return result
 }
 
 // It takes a string, converts it to a byte array, creates a new gzip reader, reads the gzip reader,
-// and returns the result as a string
+// and returns the result as a string. Input that is not gzip is returned as it is,
+// gzip is decoded within DefaultDecodeLimits.
+//
+// Deprecated: use DecodeContent, which handles every Content-Encoding.
 func DecompressGzip(Gzip string) (string, error) {
-	res, err := gzip.NewReader(bytes.NewReader([]byte(Gzip)))
+	res, err := NewContentReader(bytes.NewReader([]byte(Gzip)), "gzip", DefaultDecodeLimits)
 	if err != nil {
 		return Gzip, nil
 	}
@@ -351,17 +465,8 @@ func DecompressGzip(Gzip string) (string, error) {
 	return string(read), nil
 }
 
-// It returns true if the string is made up of digits, false otherwise
-func IsInt(s string) bool {
-	for _, c := range s {
-		if c < '0' || c > '9' {
-			return false
-		}
-	}
-	return true
-}
-
-// It takes a Go request and sets the Fiber request to match it
+// It takes a Go request and sets the Fiber request to match it, forwarding every
+// value of repeated headers and every Set-Cookie line with its attributes
 func SetGoRequestToFiber(request *fiber.Ctx, bot *gostruct.BotData) {
 	request.SendStatus(bot.HttpRequest.Response.StatusCode)
 	request.SendString(bot.HttpRequest.Response.Source)
@@ -373,638 +478,28 @@ func SetGoRequestToFiber(request *fiber.Ctx, bot *gostruct.BotData) {
 		request.Cookie(cookie)
 	}
 	for k, v := range bot.HttpRequest.Response.Headers {
-		if !strings.Contains(strings.ToLower(strings.ReplaceAll(k, " ", "")), "set-cookie") {
-			request.Set(k, v)
+		if strings.Contains(strings.ToLower(strings.ReplaceAll(k, " ", "")), "set-cookie") {
+			for _, line := range SplitHeaderValues(v) {
+				if cookie, ok := parseSetCookie(line); ok {
+					request.Cookie(cookie)
+				}
+			}
+		} else {
+			request.Response().Header.Del(k)
+			for _, value := range SplitHeaderValues(v) {
+				request.Response().Header.Add(k, value)
+			}
 		}
 	}
 }
-func GetHttp2SettingsfromClient(bot *gostruct.BotData) {
-	type ClientProfile struct {
-		Settings          map[http2.SettingID]uint32
-		SettingsOrder     []http2.SettingID
-		PseudoHeaderOrder []string
-		ConnectionFlow    uint32
-		Priorities        []http2.Priority
-	}
-	var Chrome_106 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:      65536,
-			http2.SettingEnablePush:           0,
-			http2.SettingMaxConcurrentStreams: 1000,
-			http2.SettingInitialWindowSize:    6291456,
-			http2.SettingMaxHeaderListSize:    262144,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingEnablePush,
-			http2.SettingMaxConcurrentStreams,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxHeaderListSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":authority",
-			":scheme",
-			":path",
-		},
-		ConnectionFlow: 15663105,
-	}
-
-	var Chrome_105 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:      65536,
-			http2.SettingMaxConcurrentStreams: 1000,
-			http2.SettingInitialWindowSize:    6291456,
-			http2.SettingMaxHeaderListSize:    262144,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingMaxConcurrentStreams,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxHeaderListSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":authority",
-			":scheme",
-			":path",
-		},
-		ConnectionFlow: 15663105,
-	}
-
-	var Chrome_104 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:      65536,
-			http2.SettingMaxConcurrentStreams: 1000,
-			http2.SettingInitialWindowSize:    6291456,
-			http2.SettingMaxHeaderListSize:    262144,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingMaxConcurrentStreams,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxHeaderListSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":authority",
-			":scheme",
-			":path",
-		},
-		ConnectionFlow: 15663105,
-	}
-
-	var Chrome_103 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:      65536,
-			http2.SettingMaxConcurrentStreams: 1000,
-			http2.SettingInitialWindowSize:    6291456,
-			http2.SettingMaxHeaderListSize:    262144,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingMaxConcurrentStreams,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxHeaderListSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":authority",
-			":scheme",
-			":path",
-		},
-		ConnectionFlow: 15663105,
-	}
-
-	var Safari_15_6_1 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingInitialWindowSize:    4194304,
-			http2.SettingMaxConcurrentStreams: 100,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxConcurrentStreams,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":scheme",
-			":path",
-			":authority",
-		},
-		ConnectionFlow: 10485760,
-	}
-
-	var Safari_16_0 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingInitialWindowSize:    4194304,
-			http2.SettingMaxConcurrentStreams: 100,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxConcurrentStreams,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":scheme",
-			":path",
-			":authority",
-		},
-		ConnectionFlow: 10485760,
-	}
-
-	var Safari_Ipad_15_6 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingInitialWindowSize:    2097152,
-			http2.SettingMaxConcurrentStreams: 100,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxConcurrentStreams,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":scheme",
-			":path",
-			":authority",
-		},
-		ConnectionFlow: 10485760,
-	}
-
-	var Safari_IOS_16_0 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingInitialWindowSize:    2097152,
-			http2.SettingMaxConcurrentStreams: 100,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxConcurrentStreams,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":scheme",
-			":path",
-			":authority",
-		},
-		ConnectionFlow: 10485760,
-	}
 
-	var Safari_IOS_15_5 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingInitialWindowSize:    2097152,
-			http2.SettingMaxConcurrentStreams: 100,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxConcurrentStreams,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":scheme",
-			":path",
-			":authority",
-		},
-		ConnectionFlow: 10485760,
-	}
-
-	var Safari_IOS_15_6 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingInitialWindowSize:    2097152,
-			http2.SettingMaxConcurrentStreams: 100,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxConcurrentStreams,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":scheme",
-			":path",
-			":authority",
-		},
-		ConnectionFlow: 10485760,
-	}
-
-	var Firefox_106 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:   65536,
-			http2.SettingInitialWindowSize: 131072,
-			http2.SettingMaxFrameSize:      16384,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxFrameSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":path",
-			":authority",
-			":scheme",
-		},
-		ConnectionFlow: 12517377,
-		Priorities: []http2.Priority{
-			{StreamID: 3, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    200,
-			}},
-			{StreamID: 5, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    100,
-			}},
-			{StreamID: 7, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    0,
-			}},
-			{StreamID: 9, PriorityParam: http2.PriorityParam{
-				StreamDep: 7,
-				Exclusive: false,
-				Weight:    0,
-			}},
-			{StreamID: 11, PriorityParam: http2.PriorityParam{
-				StreamDep: 3,
-				Exclusive: false,
-				Weight:    0,
-			}},
-			{StreamID: 13, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    240,
-			}},
-		},
-	}
-
-	var Firefox_105 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:   65536,
-			http2.SettingInitialWindowSize: 131072,
-			http2.SettingMaxFrameSize:      16384,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxFrameSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":path",
-			":authority",
-			":scheme",
-		},
-		ConnectionFlow: 12517377,
-		Priorities: []http2.Priority{
-			{StreamID: 3, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    200,
-			}},
-			{StreamID: 5, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    100,
-			}},
-			{StreamID: 7, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    0,
-			}},
-			{StreamID: 9, PriorityParam: http2.PriorityParam{
-				StreamDep: 7,
-				Exclusive: false,
-				Weight:    0,
-			}},
-			{StreamID: 11, PriorityParam: http2.PriorityParam{
-				StreamDep: 3,
-				Exclusive: false,
-				Weight:    0,
-			}},
-			{StreamID: 13, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    240,
-			}},
-		},
-	}
-
-	var Firefox_104 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:   65536,
-			http2.SettingInitialWindowSize: 131072,
-			http2.SettingMaxFrameSize:      16384,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxFrameSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":path",
-			":authority",
-			":scheme",
-		},
-		ConnectionFlow: 12517377,
-		Priorities: []http2.Priority{
-			{StreamID: 3, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    200,
-			}},
-			{StreamID: 5, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    100,
-			}},
-			{StreamID: 7, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    0,
-			}},
-			{StreamID: 9, PriorityParam: http2.PriorityParam{
-				StreamDep: 7,
-				Exclusive: false,
-				Weight:    0,
-			}},
-			{StreamID: 11, PriorityParam: http2.PriorityParam{
-				StreamDep: 3,
-				Exclusive: false,
-				Weight:    0,
-			}},
-			{StreamID: 13, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    240,
-			}},
-		},
-	}
-
-	var Firefox_102 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:   65536,
-			http2.SettingInitialWindowSize: 131072,
-			http2.SettingMaxFrameSize:      16384,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxFrameSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":path",
-			":authority",
-			":scheme",
-		},
-		ConnectionFlow: 12517377,
-		Priorities: []http2.Priority{
-			{StreamID: 3, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    200,
-			}},
-			{StreamID: 5, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    100,
-			}},
-			{StreamID: 7, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    0,
-			}},
-			{StreamID: 9, PriorityParam: http2.PriorityParam{
-				StreamDep: 7,
-				Exclusive: false,
-				Weight:    0,
-			}},
-			{StreamID: 11, PriorityParam: http2.PriorityParam{
-				StreamDep: 3,
-				Exclusive: false,
-				Weight:    0,
-			}},
-			{StreamID: 13, PriorityParam: http2.PriorityParam{
-				StreamDep: 0,
-				Exclusive: false,
-				Weight:    240,
-			}},
-		},
-	}
-
-	var Opera_90 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:      65536,
-			http2.SettingMaxConcurrentStreams: 1000,
-			http2.SettingInitialWindowSize:    6291456,
-			http2.SettingMaxHeaderListSize:    262144,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingMaxConcurrentStreams,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxHeaderListSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":authority",
-			":scheme",
-			":path",
-		},
-		ConnectionFlow: 15663105,
-	}
-
-	var Opera_91 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:      65536,
-			http2.SettingMaxConcurrentStreams: 1000,
-			http2.SettingInitialWindowSize:    6291456,
-			http2.SettingMaxHeaderListSize:    262144,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingMaxConcurrentStreams,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxHeaderListSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":authority",
-			":scheme",
-			":path",
-		},
-		ConnectionFlow: 15663105,
-	}
-
-	var Opera_89 = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:      65536,
-			http2.SettingMaxConcurrentStreams: 1000,
-			http2.SettingInitialWindowSize:    6291456,
-			http2.SettingMaxHeaderListSize:    262144,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingMaxConcurrentStreams,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxHeaderListSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":authority",
-			":scheme",
-			":path",
-		},
-		ConnectionFlow: 15663105,
-	}
-	var ZalandoAndroidMobile = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:      4096,
-			http2.SettingMaxConcurrentStreams: math.MaxUint32,
-			http2.SettingInitialWindowSize:    16777216,
-			http2.SettingMaxFrameSize:         16384,
-			http2.SettingMaxHeaderListSize:    math.MaxUint32,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingMaxConcurrentStreams,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxFrameSize,
-			http2.SettingMaxHeaderListSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":path",
-			":authority",
-			":scheme",
-		},
-		ConnectionFlow: 15663105,
-	}
-
-	var ZalandoIosMobile = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:      4096,
-			http2.SettingMaxConcurrentStreams: 100,
-			http2.SettingInitialWindowSize:    2097152,
-			http2.SettingMaxFrameSize:         16384,
-			http2.SettingMaxHeaderListSize:    math.MaxUint32,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingMaxConcurrentStreams,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxFrameSize,
-			http2.SettingMaxHeaderListSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":path",
-			":authority",
-			":scheme",
-		},
-		ConnectionFlow: 15663105,
-	}
-
-	var NikeIosMobile = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:      4096,
-			http2.SettingMaxConcurrentStreams: 100,
-			http2.SettingInitialWindowSize:    2097152,
-			http2.SettingMaxFrameSize:         16384,
-			http2.SettingMaxHeaderListSize:    math.MaxUint32,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingMaxConcurrentStreams,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxFrameSize,
-			http2.SettingMaxHeaderListSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":scheme",
-			":path",
-			":authority",
-		},
-		ConnectionFlow: 15663105,
-	}
-
-	var NikeAndroidMobile = ClientProfile{
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:      4096,
-			http2.SettingMaxConcurrentStreams: math.MaxUint32,
-			http2.SettingInitialWindowSize:    16777216,
-			http2.SettingMaxFrameSize:         16384,
-			http2.SettingMaxHeaderListSize:    math.MaxUint32,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingMaxConcurrentStreams,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxFrameSize,
-			http2.SettingMaxHeaderListSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":path",
-			":authority",
-			":scheme",
-		},
-		ConnectionFlow: 15663105,
-	}
-
-	var CloudflareCustom = ClientProfile{
-		//actually the h2 Settings are not relevant, because this client does only support http1
-		Settings: map[http2.SettingID]uint32{
-			http2.SettingHeaderTableSize:      4096,
-			http2.SettingMaxConcurrentStreams: math.MaxUint32,
-			http2.SettingInitialWindowSize:    16777216,
-			http2.SettingMaxFrameSize:         16384,
-			http2.SettingMaxHeaderListSize:    math.MaxUint32,
-		},
-		SettingsOrder: []http2.SettingID{
-			http2.SettingHeaderTableSize,
-			http2.SettingMaxConcurrentStreams,
-			http2.SettingInitialWindowSize,
-			http2.SettingMaxFrameSize,
-			http2.SettingMaxHeaderListSize,
-		},
-		PseudoHeaderOrder: []string{
-			":method",
-			":path",
-			":authority",
-			":scheme",
-		},
-		ConnectionFlow: 15663105,
-	}
-	var TLSClients = map[string]ClientProfile{
-		tls.HelloChrome_103.Str():    Chrome_103,
-		tls.HelloChrome_104.Str():    Chrome_104,
-		tls.HelloChrome_105.Str():    Chrome_105,
-		tls.HelloChrome_106.Str():    Chrome_106,
-		tls.HelloSafari_15_6_1.Str(): Safari_15_6_1,
-		tls.HelloSafari_16_0.Str():   Safari_16_0,
-		tls.HelloIPad_15_6.Str():     Safari_Ipad_15_6,
-		tls.HelloIOS_15_5.Str():      Safari_IOS_15_5,
-		tls.HelloIOS_15_6.Str():      Safari_IOS_15_6,
-		tls.HelloIOS_16_0.Str():      Safari_IOS_16_0,
-		tls.HelloFirefox_102.Str():   Firefox_102,
-		tls.HelloFirefox_104.Str():   Firefox_104,
-		tls.HelloFirefox_105.Str():   Firefox_105,
-		tls.HelloFirefox_106.Str():   Firefox_106,
-		tls.HelloOpera_89.Str():      Opera_89,
-		tls.HelloOpera_90.Str():      Opera_90,
-		tls.HelloOpera_91.Str():      Opera_91,
-		"zalando_android_mobile":     ZalandoAndroidMobile,
-		"zalando_ios_mobile":         ZalandoIosMobile,
-		"nike_ios_mobile":            NikeIosMobile,
-		"nike_android_mobile":        NikeAndroidMobile,
-		"cloudflare_custom":          CloudflareCustom,
-	}
-
-	if profile, exist := TLSClients[bot.HttpRequest.Request.Client.Str()]; exist {
+// It sets the HTTP/2 client profile of the bot to the one registered for its client,
+// falling back to the Chrome 106 profile when no profile is registered. The bot gets its own
+// copy of the profile.
+func GetHttp2SettingsfromClient(bot *gostruct.BotData) {
+	if profile, exist := GetHttp2Profile(bot.HttpRequest.Request.Client.Str()); exist {
 		bot.HttpRequest.Request.HTTP2TRANSPORT.ClientProfile = profile
 	} else {
-		bot.HttpRequest.Request.HTTP2TRANSPORT.ClientProfile = Chrome_106
+		bot.HttpRequest.Request.HTTP2TRANSPORT.ClientProfile = copyClientProfile(chrome_106)
 	}
 }
@@ -0,0 +1,303 @@
+package gotools
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	gostruct "github.com/kawacode/gostruct"
+)
+
+// HAR is an HTTP Archive 1.2 document as exported by the Chrome and Firefox devtools
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog is the root of a HAR document
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+// HARCreator names the application that created a HAR document
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry is a request and its response
+type HAREntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+}
+
+// HARNameValue is a header, cookie, query or form parameter
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARRequest is the request of a HAR entry
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARPostData is the body of a HAR request
+type HARPostData struct {
+	MimeType string         `json:"mimeType"`
+	Text     string         `json:"text"`
+	Params   []HARNameValue `json:"params,omitempty"`
+}
+
+// HARResponse is the response of a HAR entry
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARContent is the body of a HAR response
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings are the phases of a HAR entry in milliseconds
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// It returns an empty HAR document to add executed bots to
+func NewHAR() *HAR {
+	return &HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "gotools", Version: "1.0"},
+		Entries: []HAREntry{},
+	}}
+}
+
+// It reads a HAR file and returns its requests as bots, see ParseHAR
+func LoadHAR(path string) ([]*gostruct.BotData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseHAR(data)
+}
+
+// It converts the entries of a HAR document into bots, one per entry. Headers keep
+// their order in HeaderOrderKey and HTTP/2 pseudo headers their order in the
+// HTTP/2 profile. Cookies go to the request cookies instead of a Cookie header.
+// The client is picked from the User-Agent, and the recorded response is kept.
+func ParseHAR(data []byte) ([]*gostruct.BotData, error) {
+	var har HAR
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("gotools: har: %w", err)
+	}
+	var bots []*gostruct.BotData
+	for i, entry := range har.Log.Entries {
+		bot, err := harEntryToBot(entry)
+		if err != nil {
+			return nil, fmt.Errorf("gotools: har entry %d: %w", i, err)
+		}
+		bots = append(bots, bot)
+	}
+	return bots, nil
+}
+
+// It converts a single HAR entry into a bot
+func harEntryToBot(entry HAREntry) (*gostruct.BotData, error) {
+	bot := new(gostruct.BotData)
+	request := &bot.HttpRequest.Request
+	if _, err := url.Parse(entry.Request.URL); err != nil {
+		return nil, err
+	}
+	request.URL = entry.Request.URL
+	request.Method = strings.ToUpper(entry.Request.Method)
+	switch strings.ToLower(entry.Request.HTTPVersion) {
+	case "h2", "h3", "http/2", "http/2.0", "http/3", "http/3.0":
+		request.Protocol = "2"
+	default:
+		request.Protocol = "1"
+	}
+	var (
+		headers OrderedHeaders
+		pseudo  []string
+		order   []string
+	)
+	for _, header := range entry.Request.Headers {
+		switch {
+		case strings.HasPrefix(header.Name, ":"):
+			pseudo = append(pseudo, strings.ToLower(header.Name))
+		case strings.EqualFold(header.Name, "Cookie") && len(entry.Request.Cookies) > 0:
+			order = append(order, header.Name)
+		default:
+			headers.Add(header.Name, header.Value)
+			order = append(order, header.Name)
+		}
+	}
+	headers.SetToBot(bot)
+	request.HeaderOrderKey = order
+	if len(entry.Request.Cookies) > 0 {
+		request.Cookies = make(map[string]string)
+		for _, cookie := range entry.Request.Cookies {
+			request.Cookies[cookie.Name] = cookie.Value
+		}
+	}
+	if entry.Request.PostData != nil {
+		request.Payload = entry.Request.PostData.Text
+		if request.Payload == "" && len(entry.Request.PostData.Params) > 0 {
+			form := url.Values{}
+			for _, param := range entry.Request.PostData.Params {
+				form.Add(param.Name, param.Value)
+			}
+			request.Payload = form.Encode()
+		}
+	}
+	if client := HelloClientFromUserAgent(headers.Get("User-Agent")); client != nil {
+		request.Client = *client
+	}
+	GetHttp2SettingsfromClient(bot)
+	if len(pseudo) > 0 {
+		request.HTTP2TRANSPORT.ClientProfile.PseudoHeaderOrder = pseudo
+	}
+	response := &bot.HttpRequest.Response
+	response.StatusCode = entry.Response.Status
+	response.Source = entry.Response.Content.Text
+	if entry.Response.Content.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(entry.Response.Content.Text)
+		if err != nil {
+			return nil, err
+		}
+		response.Source = string(decoded)
+	}
+	responseheaders := make(map[string][]string)
+	for _, header := range entry.Response.Headers {
+		responseheaders[header.Name] = append(responseheaders[header.Name], header.Value)
+	}
+	response.Headers = MapStringSliceToMapStringLossless(responseheaders)
+	response.Cookies = make(map[string]string)
+	for _, cookie := range entry.Response.Cookies {
+		response.Cookies[cookie.Name] = cookie.Value
+	}
+	return bot, nil
+}
+
+// It adds an executed bot's request and response to the HAR document. started is
+// when the request was sent and elapsed how long it took until the response was read.
+func (har *HAR) AddBot(bot *gostruct.BotData, started time.Time, elapsed time.Duration) {
+	request := &bot.HttpRequest.Request
+	response := &bot.HttpRequest.Response
+	httpversion := "http/1.1"
+	if request.Protocol == "2" {
+		httpversion = "h2"
+	}
+	entry := HAREntry{
+		StartedDateTime: started.Format(time.RFC3339Nano),
+		Time:            float64(elapsed) / float64(time.Millisecond),
+		Timings:         HARTimings{Wait: float64(elapsed) / float64(time.Millisecond)},
+	}
+	entry.Request = HARRequest{
+		Method:      request.Method,
+		URL:         request.URL,
+		HTTPVersion: httpversion,
+		Cookies:     []HARNameValue{},
+		Headers:     []HARNameValue{},
+		QueryString: []HARNameValue{},
+		HeadersSize: -1,
+		BodySize:    len(request.Payload),
+	}
+	if parsed, err := url.Parse(request.URL); err == nil {
+		if request.Protocol == "2" {
+			host, _ := HostFromURL(request.URL)
+			values := map[string]string{":method": request.Method, ":authority": host, ":scheme": parsed.Scheme, ":path": parsed.RequestURI()}
+			for _, pseudo := range request.HTTP2TRANSPORT.ClientProfile.PseudoHeaderOrder {
+				entry.Request.Headers = append(entry.Request.Headers, HARNameValue{pseudo, values[pseudo]})
+			}
+		}
+		for _, query := range strings.Split(parsed.RawQuery, "&") {
+			if query == "" {
+				continue
+			}
+			name, value, _ := strings.Cut(query, "=")
+			name, _ = url.QueryUnescape(name)
+			value, _ = url.QueryUnescape(value)
+			entry.Request.QueryString = append(entry.Request.QueryString, HARNameValue{name, value})
+		}
+	}
+	for _, field := range OrderedHeadersFromBot(bot) {
+		entry.Request.Headers = append(entry.Request.Headers, HARNameValue{field.Name, field.Value})
+	}
+	for _, name := range sortedKeys(request.Cookies) {
+		entry.Request.Cookies = append(entry.Request.Cookies, HARNameValue{name, request.Cookies[name]})
+	}
+	if request.Payload != "" {
+		mimetype, _ := headerValue(request.Headers, "Content-Type")
+		entry.Request.PostData = &HARPostData{MimeType: mimetype, Text: request.Payload}
+	}
+	mimetype, _ := headerValue(response.Headers, "Content-Type")
+	entry.Response = HARResponse{
+		Status:      response.StatusCode,
+		StatusText:  http.StatusText(response.StatusCode),
+		HTTPVersion: httpversion,
+		Cookies:     []HARNameValue{},
+		Headers:     []HARNameValue{},
+		Content:     HARContent{Size: len(response.Source), MimeType: mimetype, Text: response.Source},
+		HeadersSize: -1,
+		BodySize:    len(response.Source),
+	}
+	if !utf8.ValidString(response.Source) {
+		entry.Response.Content.Text = base64.StdEncoding.EncodeToString([]byte(response.Source))
+		entry.Response.Content.Encoding = "base64"
+	}
+	entry.Response.RedirectURL, _ = headerValue(response.Headers, "Location")
+	for _, name := range sortedKeys(response.Headers) {
+		for _, value := range SplitHeaderValues(response.Headers[name]) {
+			entry.Response.Headers = append(entry.Response.Headers, HARNameValue{name, value})
+		}
+	}
+	for _, name := range sortedKeys(response.Cookies) {
+		entry.Response.Cookies = append(entry.Response.Cookies, HARNameValue{name, response.Cookies[name]})
+	}
+	har.Log.Entries = append(har.Log.Entries, entry)
+}
+
+// It returns the keys of a map in sorted order
+func sortedKeys(values map[string]string) []string {
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
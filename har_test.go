@@ -0,0 +1,137 @@
+package gotools
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testHAR = `{"log": {"version": "1.2", "creator": {"name": "Firefox", "version": "105.0"}, "entries": [{
+	"startedDateTime": "2022-10-01T10:00:00.000Z",
+	"time": 120,
+	"request": {
+		"method": "POST",
+		"url": "https://example.com/login?next=%2Fhome",
+		"httpVersion": "HTTP/2",
+		"cookies": [{"name": "session", "value": "abc"}],
+		"headers": [
+			{"name": ":method", "value": "POST"},
+			{"name": ":path", "value": "/login?next=%2Fhome"},
+			{"name": ":authority", "value": "example.com"},
+			{"name": ":scheme", "value": "https"},
+			{"name": "user-agent", "value": "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:105.0) Gecko/20100101 Firefox/105.0"},
+			{"name": "accept", "value": "*/*"},
+			{"name": "content-type", "value": "application/x-www-form-urlencoded"},
+			{"name": "cookie", "value": "session=abc"}
+		],
+		"queryString": [{"name": "next", "value": "/home"}],
+		"postData": {"mimeType": "application/x-www-form-urlencoded", "text": "user=a&pass=b"},
+		"headersSize": -1,
+		"bodySize": 13
+	},
+	"response": {
+		"status": 302,
+		"statusText": "Found",
+		"httpVersion": "HTTP/2",
+		"cookies": [{"name": "id", "value": "1"}, {"name": "theme", "value": "dark"}],
+		"headers": [
+			{"name": "location", "value": "/home"},
+			{"name": "set-cookie", "value": "id=1; Path=/; HttpOnly"},
+			{"name": "set-cookie", "value": "theme=dark; Max-Age=3600"}
+		],
+		"content": {"size": 2, "mimeType": "text/plain", "text": "b2s=", "encoding": "base64"},
+		"redirectURL": "/home",
+		"headersSize": -1,
+		"bodySize": 2
+	},
+	"cache": {},
+	"timings": {"send": 1, "wait": 100, "receive": 19}
+}]}}`
+
+func TestParseHAR(t *testing.T) {
+	bots, err := ParseHAR([]byte(testHAR))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bots) != 1 {
+		t.Fatalf("got %d bots, want 1", len(bots))
+	}
+	request := bots[0].HttpRequest.Request
+	if request.Method != "POST" || request.Protocol != "2" || request.Payload != "user=a&pass=b" {
+		t.Errorf("got %s protocol %s payload %q", request.Method, request.Protocol, request.Payload)
+	}
+	if want := []string{"user-agent", "accept", "content-type", "cookie"}; !reflect.DeepEqual(request.HeaderOrderKey, want) {
+		t.Errorf("header order %v, want %v", request.HeaderOrderKey, want)
+	}
+	if _, exist := request.Headers["cookie"]; exist {
+		t.Errorf("the cookie header was kept next to the cookies: %v", request.Headers)
+	}
+	if want := map[string]string{"session": "abc"}; !reflect.DeepEqual(request.Cookies, want) {
+		t.Errorf("cookies %v, want %v", request.Cookies, want)
+	}
+	if want := []string{":method", ":path", ":authority", ":scheme"}; !reflect.DeepEqual(request.HTTP2TRANSPORT.ClientProfile.PseudoHeaderOrder, want) {
+		t.Errorf("pseudo header order %v, want %v", request.HTTP2TRANSPORT.ClientProfile.PseudoHeaderOrder, want)
+	}
+	if request.Client.Str() != "Firefox-105" {
+		t.Errorf("client %s, want Firefox-105", request.Client.Str())
+	}
+	response := bots[0].HttpRequest.Response
+	if response.StatusCode != 302 || response.Source != "ok" {
+		t.Errorf("response %d %q, want 302 \"ok\"", response.StatusCode, response.Source)
+	}
+}
+
+func TestHARRoundTrip(t *testing.T) {
+	bots, err := ParseHAR([]byte(testHAR))
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := time.Date(2022, 10, 1, 10, 0, 0, 0, time.UTC)
+	har := NewHAR()
+	har.AddBot(bots[0], started, 150*time.Millisecond)
+	if len(har.Log.Entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(har.Log.Entries))
+	}
+	entry := har.Log.Entries[0]
+	var pseudo []string
+	for _, header := range entry.Request.Headers {
+		if header.Name[0] == ':' {
+			pseudo = append(pseudo, header.Name)
+		}
+	}
+	if want := []string{":method", ":path", ":authority", ":scheme"}; !reflect.DeepEqual(pseudo, want) {
+		t.Errorf("exported pseudo header order %v, want %v", pseudo, want)
+	}
+	if want := (HARNameValue{":path", "/login?next=%2Fhome"}); entry.Request.Headers[1] != want {
+		t.Errorf("exported :path %v, want %v", entry.Request.Headers[1], want)
+	}
+	var setcookies []string
+	for _, header := range entry.Response.Headers {
+		if header.Name == "set-cookie" {
+			setcookies = append(setcookies, header.Value)
+		}
+	}
+	if want := []string{"id=1; Path=/; HttpOnly", "theme=dark; Max-Age=3600"}; !reflect.DeepEqual(setcookies, want) {
+		t.Errorf("exported Set-Cookie lines %q, want %q", setcookies, want)
+	}
+	if entry.StartedDateTime != "2022-10-01T10:00:00Z" || entry.Time != 150 || entry.Timings.Wait != 150 {
+		t.Errorf("exported started %s time %g wait %g, want 2022-10-01T10:00:00Z 150 150", entry.StartedDateTime, entry.Time, entry.Timings.Wait)
+	}
+	if entry.Response.RedirectURL != "/home" || entry.Response.Content.Text != "ok" {
+		t.Errorf("exported redirect %q content %q", entry.Response.RedirectURL, entry.Response.Content.Text)
+	}
+	data, err := json.Marshal(har)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := ParseHAR(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second := bots[0].HttpRequest.Request, again[0].HttpRequest.Request
+	if first.URL != second.URL || first.Payload != second.Payload || !reflect.DeepEqual(first.Cookies, second.Cookies) ||
+		!reflect.DeepEqual(first.HTTP2TRANSPORT.ClientProfile.PseudoHeaderOrder, second.HTTP2TRANSPORT.ClientProfile.PseudoHeaderOrder) {
+		t.Errorf("round trip changed the request: %+v, want %+v", second, first)
+	}
+}
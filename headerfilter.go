@@ -0,0 +1,102 @@
+package gotools
+
+import (
+	"strings"
+)
+
+// hopByHopHeaders are the connection-specific headers of RFC 9110 section 7.6.1,
+// which a proxy must not forward and HTTP/2 forbids (RFC 9113 section 8.2.2).
+// Content-Length is dropped too, the transport sets it from the body.
+var hopByHopHeaders = map[string]bool{
+	"connection":        true,
+	"keep-alive":        true,
+	"proxy-connection":  true,
+	"transfer-encoding": true,
+	"upgrade":           true,
+	"te":                true,
+	"content-length":    true,
+}
+
+// headerFilter decides which headers of a request may be forwarded
+type headerFilter struct {
+	protocol string
+	drop     map[string]bool
+	allow    map[string]bool
+}
+
+// It builds the filter for a request whose Connection header has the given values
+func newHeaderFilter(connection []string, protocol string, allow []string) headerFilter {
+	filter := headerFilter{
+		protocol: protocol,
+		drop:     make(map[string]bool),
+		allow:    make(map[string]bool),
+	}
+	for name := range hopByHopHeaders {
+		filter.drop[name] = true
+	}
+	for _, value := range connection {
+		for _, token := range strings.Split(value, ",") {
+			if token = strings.ToLower(strings.TrimSpace(token)); token != "" {
+				filter.drop[token] = true
+			}
+		}
+	}
+	for _, name := range allow {
+		filter.allow[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	return filter
+}
+
+// It builds the filter MapStringToMapStringSlice and OrderedHeaders.Fhttp send
+// headers through. HTTP/2 drops the connection-specific headers RFC 9113 forbids
+// and lowercases names, HTTP/1.1 keeps them as they are part of the fingerprint.
+// Content-Length is always dropped, the transport sets it from the body.
+func newSendFilter(connection []string, protocol string) headerFilter {
+	if protocol == "2" {
+		return newHeaderFilter(connection, protocol, nil)
+	}
+	return headerFilter{protocol: protocol, drop: map[string]bool{"content-length": true}}
+}
+
+// It reports whether the header may be forwarded
+func (f headerFilter) keep(name string, value string) bool {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if f.allow[key] {
+		return true
+	}
+	if key == "te" && strings.EqualFold(strings.TrimSpace(value), "trailers") {
+		return true
+	}
+	return !f.drop[key]
+}
+
+// It returns the name the header is sent with: lowercased for HTTP/2, unchanged otherwise
+func (f headerFilter) name(name string) string {
+	if f.protocol == "2" {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// It removes the hop-by-hop headers a proxy must not forward: Connection, Keep-Alive,
+// Proxy-Connection, Transfer-Encoding, Upgrade, TE unless it is "trailers",
+// Content-Length and every header listed in Connection. For protocol "2" the names
+// are lowercased as HTTP/2 requires. Headers in allow are always kept.
+// Use it when forwarding the headers of a proxied request, like a fiber request
+// after RemoveKawaCodeHeaders, before MapStringToMapStringSlice.
+func FilterHeaders(headers map[string]string, protocol string, allow ...string) map[string]string {
+	var connection []string
+	for k, v := range headers {
+		if strings.EqualFold(strings.TrimSpace(k), "Connection") {
+			connection = append(connection, v)
+		}
+	}
+	filter := newHeaderFilter(connection, protocol, allow)
+	result := make(map[string]string)
+	for k, v := range headers {
+		if filter.keep(k, v) {
+			result[filter.name(k)] = v
+		}
+	}
+	return result
+}
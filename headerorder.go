@@ -0,0 +1,164 @@
+package gotools
+
+import (
+	"sort"
+	"strings"
+
+	gostruct "github.com/kawacode/gostruct"
+)
+
+// defaultHeaderOrders is the order in which each engine sends its headers, covering
+// navigations, fetches and form posts
+var defaultHeaderOrders = map[string][]string{
+	engineChromium: {
+		"host",
+		"connection",
+		"content-length",
+		"pragma",
+		"cache-control",
+		"sec-ch-ua",
+		"sec-ch-ua-mobile",
+		"sec-ch-ua-platform",
+		"upgrade-insecure-requests",
+		"origin",
+		"content-type",
+		"user-agent",
+		"accept",
+		"sec-fetch-site",
+		"sec-fetch-mode",
+		"sec-fetch-user",
+		"sec-fetch-dest",
+		"referer",
+		"accept-encoding",
+		"accept-language",
+		"cookie",
+	},
+	engineFirefox: {
+		"host",
+		"user-agent",
+		"accept",
+		"accept-language",
+		"accept-encoding",
+		"content-type",
+		"content-length",
+		"origin",
+		"connection",
+		"referer",
+		"cookie",
+		"upgrade-insecure-requests",
+		"sec-fetch-dest",
+		"sec-fetch-mode",
+		"sec-fetch-site",
+		"sec-fetch-user",
+		"pragma",
+		"cache-control",
+		"te",
+	},
+	engineSafari: {
+		"host",
+		"content-type",
+		"accept",
+		"origin",
+		"sec-fetch-site",
+		"cookie",
+		"content-length",
+		"sec-fetch-dest",
+		"accept-language",
+		"sec-fetch-mode",
+		"user-agent",
+		"referer",
+		"accept-encoding",
+		"connection",
+	},
+	engineOkHttp: {
+		"content-type",
+		"content-length",
+		"host",
+		"connection",
+		"accept-encoding",
+		"cookie",
+		"user-agent",
+	},
+}
+
+// It returns the header order of the bot's browser: the order of a loaded profile
+// for its client, else the built-in order of the client's engine, else the order
+// of the engine its User-Agent claims. It returns nil for unknown clients.
+func GetDefaultHeaderOrder(bot *gostruct.BotData) []string {
+	if profile, exist := GetBrowserProfile(bot.HttpRequest.Request.Client.Str()); exist && len(profile.HeaderOrder) > 0 {
+		return profile.HeaderOrder
+	}
+	engine := clientEngine(bot.HttpRequest.Request.Client.Client)
+	if engine == "" {
+		if useragent, exist := headerValue(bot.HttpRequest.Request.Headers, "User-Agent"); exist {
+			browser, _ := userAgentBrowser(useragent)
+			engine = clientEngine(browser)
+		}
+	}
+	return defaultHeaderOrders[engine]
+}
+
+// It merges a user supplied header order with a browser's order. Headers in the
+// user order keep their position, the other headers are placed where the browser
+// would put them, and headers neither order knows are appended sorted by name.
+func MergeHeaderOrder(userorder []string, browserorder []string, headers []string) []string {
+	browserindex := make(map[string]int)
+	for i, key := range browserorder {
+		browserindex[strings.ToLower(key)] = i
+	}
+	var result []string
+	seen := make(map[string]bool)
+	for _, key := range userorder {
+		key = strings.ReplaceAll(key, " ", "")
+		if !seen[strings.ToLower(key)] {
+			seen[strings.ToLower(key)] = true
+			result = append(result, key)
+		}
+	}
+	var missing, unknown []string
+	for _, key := range headers {
+		key = strings.ToLower(strings.ReplaceAll(key, " ", ""))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if _, exist := browserindex[key]; exist {
+			missing = append(missing, key)
+		} else {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return browserindex[missing[i]] < browserindex[missing[j]]
+	})
+	for _, key := range missing {
+		result = insertByBrowserOrder(result, key, browserindex)
+	}
+	sort.Strings(unknown)
+	return append(result, unknown...)
+}
+
+// It inserts key right after the last header of order that the browser sends before
+// it, or before the first one the browser sends after it
+func insertByBrowserOrder(order []string, key string, browserindex map[string]int) []string {
+	position := browserindex[key]
+	at := -1
+	for i, existing := range order {
+		if index, exist := browserindex[strings.ToLower(existing)]; exist && index < position {
+			at = i + 1
+		}
+	}
+	if at == -1 {
+		at = len(order)
+		for i, existing := range order {
+			if index, exist := browserindex[strings.ToLower(existing)]; exist && index > position {
+				at = i
+				break
+			}
+		}
+	}
+	order = append(order, "")
+	copy(order[at+1:], order[at:])
+	order[at] = key
+	return order
+}
@@ -0,0 +1,152 @@
+package gotools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// firstNames and lastNames are common English first and last names for Rand.Name
+var firstNames = []string{
+	"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda",
+	"William", "Elizabeth", "David", "Barbara", "Richard", "Susan", "Joseph", "Jessica",
+	"Thomas", "Sarah", "Charles", "Karen", "Daniel", "Lisa", "Matthew", "Nancy",
+	"Anthony", "Sandra", "Mark", "Ashley", "Steven", "Emily", "Andrew", "Michelle",
+	"Joshua", "Amanda", "Kevin", "Melissa", "Brian", "Laura", "Ryan", "Emma",
+	"Jacob", "Olivia", "Nicholas", "Sophia", "Tyler", "Hannah", "Lucas", "Chloe",
+}
+
+var lastNames = []string{
+	"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis",
+	"Rodriguez", "Martinez", "Hernandez", "Lopez", "Wilson", "Anderson", "Thomas", "Taylor",
+	"Moore", "Jackson", "Martin", "Lee", "Thompson", "White", "Harris", "Clark",
+	"Lewis", "Robinson", "Walker", "Young", "Allen", "King", "Wright", "Scott",
+	"Hill", "Green", "Adams", "Baker", "Nelson", "Carter", "Mitchell", "Roberts",
+}
+
+// imeiReportingBodies are the first two digits of the TAC of most phones in use
+var imeiReportingBodies = []string{"35", "86", "01", "99"}
+
+// It returns n random bytes
+func (r *Rand) bytes(n int) []byte {
+	result := make([]byte, n)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.source.Read(result)
+	return result
+}
+
+// It returns a random version 4 UUID like "3b241101-e2bb-4255-8caf-4136c566a962"
+func (r *Rand) UUID() string {
+	b := r.bytes(16)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// It returns a random iOS device identifier, an upper case UUID like identifierForVendor
+func (r *Rand) DeviceID() string {
+	return strings.ToUpper(r.UUID())
+}
+
+// It returns a random Android ID, 16 lower case hex characters
+func (r *Rand) AndroidID() string {
+	return r.HexNonce(8)
+}
+
+// It returns size random bytes as lower case hex
+func (r *Rand) HexNonce(size int) string {
+	if size <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%x", r.bytes(size))
+}
+
+// It returns a random 15 digit IMEI with a valid Luhn check digit
+func (r *Rand) IMEI() string {
+	prefix, _ := r.Choice(imeiReportingBodies)
+	digits, _ := r.String(12, CharsetDigits)
+	imei := prefix + digits
+	check, _ := LuhnCheckDigit(imei)
+	return imei + strconv.Itoa(check)
+}
+
+// It returns a random first and last name
+func (r *Rand) Name() (string, string) {
+	first, _ := r.Choice(firstNames)
+	last, _ := r.Choice(lastNames)
+	return first, last
+}
+
+// It returns a random email local part built from a name the way people pick them,
+// like "emma.wilson", "ewilson94" or "emma_wilson"
+func (r *Rand) EmailLocalPart() string {
+	first, last := r.Name()
+	first, last = strings.ToLower(first), strings.ToLower(last)
+	year, _ := r.IntRange(1965, 2005)
+	number, _ := r.IntRange(1, 999)
+	patterns := []string{
+		first + "." + last,
+		first + last + strconv.Itoa(number),
+		first[:1] + last + strconv.Itoa(year%100),
+		first + "_" + last,
+		first + "." + last + strconv.Itoa(year),
+		last + "." + first,
+	}
+	localpart, _ := r.Choice(patterns)
+	return localpart
+}
+
+// It returns the Luhn check digit for a string of digits, as used by IMEIs and card numbers
+func LuhnCheckDigit(number string) (int, error) {
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if digit < 0 || digit > 9 {
+			return 0, fmt.Errorf("gotools: luhn: %q is not a string of digits", number)
+		}
+		if (len(number)-1-i)%2 == 0 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return (10 - sum%10) % 10, nil
+}
+
+// It returns a random version 4 UUID from DefaultRand
+func RandomUUID() string {
+	return DefaultRand.UUID()
+}
+
+// It returns a random iOS device identifier from DefaultRand
+func RandomDeviceID() string {
+	return DefaultRand.DeviceID()
+}
+
+// It returns a random Android ID from DefaultRand
+func RandomAndroidID() string {
+	return DefaultRand.AndroidID()
+}
+
+// It returns size random bytes from DefaultRand as lower case hex
+func RandomHexNonce(size int) string {
+	return DefaultRand.HexNonce(size)
+}
+
+// It returns a random IMEI from DefaultRand
+func RandomIMEI() string {
+	return DefaultRand.IMEI()
+}
+
+// It returns a random first and last name from DefaultRand
+func RandomName() (string, string) {
+	return DefaultRand.Name()
+}
+
+// It returns a random email local part from DefaultRand
+func RandomEmailLocalPart() string {
+	return DefaultRand.EmailLocalPart()
+}
@@ -0,0 +1,261 @@
+package gotools
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	http2 "github.com/kawacode/fhttp/http2"
+	gostruct "github.com/kawacode/gostruct"
+	tls "github.com/kawacode/utls"
+)
+
+// The x-kc-* control headers read by ParseKawaCodeHeaders and removed by RemoveKawaCodeHeaders
+const (
+	KawaCodeHeaderClient        = "X-KC-Client"
+	KawaCodeHeaderJA3           = "X-KC-JA3"
+	KawaCodeHeaderProtocol      = "X-KC-Protocol"
+	KawaCodeHeaderProxy         = "X-KC-Proxy"
+	KawaCodeHeaderTimeout       = "X-KC-Timeout"
+	KawaCodeHeaderHeaderOrder   = "X-KC-Header-Order"
+	KawaCodeHeaderPHeaderOrder  = "X-KC-PHeader-Order"
+	KawaCodeHeaderH2Fingerprint = "X-KC-H2-Fingerprint"
+	KawaCodeHeaderRedirects     = "X-KC-Redirects"
+)
+
+// KawaCodeHeaderError is returned by ParseKawaCodeHeaders for a control header with an invalid value
+type KawaCodeHeaderError struct {
+	Header string
+	Value  string
+	Reason string
+}
+
+func (e *KawaCodeHeaderError) Error() string {
+	return fmt.Sprintf("gotools: invalid %s header %q: %s", e.Header, e.Value, e.Reason)
+}
+
+// pseudoHeaderLetters maps the letters of an HTTP/2 fingerprint to pseudo headers
+var pseudoHeaderLetters = map[string]string{
+	"m": ":method",
+	"a": ":authority",
+	"s": ":scheme",
+	"p": ":path",
+}
+
+// It parses an Akamai style HTTP/2 fingerprint, SETTINGS|WINDOW_UPDATE|PRIORITY|PSEUDO_HEADER_ORDER,
+// like "1:65536;3:1000;4:6291456;6:262144|15663105|0|m,a,s,p", into a validated profile.
+// PRIORITY is "0" or a comma separated list of stream:exclusive:dependency:weight.
+func ParseHttp2Fingerprint(fingerprint string) (ClientProfile, error) {
+	var profile ClientProfile
+	parts := strings.Split(fingerprint, "|")
+	if len(parts) != 4 {
+		return profile, fmt.Errorf("want 4 parts separated by |, got %d", len(parts))
+	}
+	profile.Settings = make(map[http2.SettingID]uint32)
+	for _, setting := range strings.Split(parts[0], ";") {
+		if setting == "" {
+			continue
+		}
+		keyvalue := strings.SplitN(setting, ":", 2)
+		if len(keyvalue) != 2 {
+			return profile, fmt.Errorf("setting %q is not id:value", setting)
+		}
+		id, err := strconv.ParseUint(keyvalue[0], 10, 16)
+		if err != nil {
+			return profile, fmt.Errorf("setting %q: %w", setting, err)
+		}
+		value, err := strconv.ParseUint(keyvalue[1], 10, 32)
+		if err != nil {
+			return profile, fmt.Errorf("setting %q: %w", setting, err)
+		}
+		profile.Settings[http2.SettingID(id)] = uint32(value)
+		profile.SettingsOrder = append(profile.SettingsOrder, http2.SettingID(id))
+	}
+	flow, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return profile, fmt.Errorf("window update %q: %w", parts[1], err)
+	}
+	profile.ConnectionFlow = uint32(flow)
+	if parts[2] != "0" && parts[2] != "" {
+		for _, priority := range strings.Split(parts[2], ",") {
+			fields := strings.Split(priority, ":")
+			if len(fields) != 4 {
+				return profile, fmt.Errorf("priority %q is not stream:exclusive:dependency:weight", priority)
+			}
+			var numbers [4]uint64
+			for i, field := range fields {
+				numbers[i], err = strconv.ParseUint(field, 10, 32)
+				if err != nil {
+					return profile, fmt.Errorf("priority %q: %w", priority, err)
+				}
+			}
+			if numbers[1] > 1 || numbers[3] < 1 || numbers[3] > 256 {
+				return profile, fmt.Errorf("priority %q needs exclusive 0 or 1 and a weight from 1 to 256", priority)
+			}
+			profile.Priorities = append(profile.Priorities, http2.Priority{
+				StreamID: uint32(numbers[0]),
+				PriorityParam: http2.PriorityParam{
+					Exclusive: numbers[1] == 1,
+					StreamDep: uint32(numbers[2]),
+					Weight:    uint8(numbers[3] - 1),
+				},
+			})
+		}
+	}
+	for _, letter := range strings.Split(parts[3], ",") {
+		pseudo, exist := pseudoHeaderLetters[strings.TrimSpace(letter)]
+		if !exist {
+			return profile, fmt.Errorf("unknown pseudo header %q", letter)
+		}
+		profile.PseudoHeaderOrder = append(profile.PseudoHeaderOrder, pseudo)
+	}
+	if err := ValidateHttp2Profile(profile); err != nil {
+		return profile, err
+	}
+	return profile, nil
+}
+
+// It splits a comma separated header list and drops empty entries
+func splitHeaderList(value string) []string {
+	var result []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// It configures the bot from the x-kc-* control headers of a request, so a proxy
+// endpoint can choose the fingerprint per request. Header names are matched
+// case-insensitively, headers that are not set leave the bot unchanged:
+//
+//	X-KC-Client          a GetHelloClient name like HelloChrome_106, also sets its HTTP/2 profile
+//	X-KC-JA3             a JA3 string, checked with ParseJA3
+//	X-KC-Protocol        "1" for HTTP/1.1 or "2" for HTTP/2
+//	X-KC-Proxy           a proxy URL with an http, https, socks5 or socks5h scheme
+//	X-KC-Timeout         the timeout as a positive integer
+//	X-KC-Header-Order    a comma separated header order for HeaderOrderKey
+//	X-KC-PHeader-Order   a comma separated order of :method, :authority, :scheme and :path
+//	X-KC-H2-Fingerprint  an HTTP/2 fingerprint, see ParseHttp2Fingerprint
+//	X-KC-Redirects       the maximum number of redirects to follow, 0 disables them
+//
+// An invalid value returns a *KawaCodeHeaderError and leaves the bot unchanged: every
+// header is checked before the bot is configured.
+func ParseKawaCodeHeaders(headers map[string]string, bot *gostruct.BotData) error {
+	get := func(name string) (string, bool) {
+		value, exist := headerValue(headers, name)
+		return strings.TrimSpace(value), exist
+	}
+	request := &bot.HttpRequest.Request
+	var (
+		protocol    = request.Protocol
+		client      *tls.ClientHelloID
+		ja3         string
+		profile     *ClientProfile
+		pseudoorder []string
+		headerorder []string
+		proxy       string
+		timeout     string
+		redirects   string
+	)
+	if v, exist := get(KawaCodeHeaderProtocol); exist {
+		if v != "1" && v != "2" {
+			return &KawaCodeHeaderError{KawaCodeHeaderProtocol, v, `must be "1" or "2"`}
+		}
+		protocol = v
+	}
+	if v, exist := get(KawaCodeHeaderClient); exist {
+		client = GetHelloClient(v)
+		if client == &tls.HelloChrome_Auto && !strings.EqualFold(v, "HelloChrome_Auto") {
+			return &KawaCodeHeaderError{KawaCodeHeaderClient, v, "unknown client"}
+		}
+	}
+	if v, exist := get(KawaCodeHeaderJA3); exist {
+		if _, err := ParseJA3(v, protocol); err != nil {
+			return &KawaCodeHeaderError{KawaCodeHeaderJA3, v, err.Error()}
+		}
+		ja3 = v
+	}
+	if v, exist := get(KawaCodeHeaderH2Fingerprint); exist {
+		parsed, err := ParseHttp2Fingerprint(v)
+		if err != nil {
+			return &KawaCodeHeaderError{KawaCodeHeaderH2Fingerprint, v, err.Error()}
+		}
+		profile = &parsed
+	}
+	if v, exist := get(KawaCodeHeaderPHeaderOrder); exist {
+		pseudoorder = splitHeaderList(v)
+		seen := make(map[string]bool)
+		for _, pseudo := range pseudoorder {
+			switch pseudo {
+			case ":method", ":authority", ":scheme", ":path":
+				seen[pseudo] = true
+			}
+		}
+		if len(pseudoorder) != 4 || len(seen) != 4 {
+			return &KawaCodeHeaderError{KawaCodeHeaderPHeaderOrder, v, "must list :method, :authority, :scheme and :path once each"}
+		}
+	}
+	if v, exist := get(KawaCodeHeaderHeaderOrder); exist {
+		headerorder = splitHeaderList(v)
+		if len(headerorder) == 0 {
+			return &KawaCodeHeaderError{KawaCodeHeaderHeaderOrder, v, "is empty"}
+		}
+	}
+	if v, exist := get(KawaCodeHeaderProxy); exist {
+		parsed, err := url.Parse(v)
+		if err != nil {
+			return &KawaCodeHeaderError{KawaCodeHeaderProxy, v, err.Error()}
+		}
+		switch parsed.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return &KawaCodeHeaderError{KawaCodeHeaderProxy, v, "scheme must be http, https, socks5 or socks5h"}
+		}
+		if parsed.Hostname() == "" {
+			return &KawaCodeHeaderError{KawaCodeHeaderProxy, v, "has no host"}
+		}
+		proxy = v
+	}
+	if v, exist := get(KawaCodeHeaderTimeout); exist {
+		if seconds, err := strconv.Atoi(v); err != nil || seconds <= 0 {
+			return &KawaCodeHeaderError{KawaCodeHeaderTimeout, v, "must be a positive integer"}
+		}
+		timeout = v
+	}
+	if v, exist := get(KawaCodeHeaderRedirects); exist {
+		if maximum, err := strconv.Atoi(v); err != nil || maximum < 0 {
+			return &KawaCodeHeaderError{KawaCodeHeaderRedirects, v, "must be a non-negative integer"}
+		}
+		redirects = v
+	}
+	request.Protocol = protocol
+	if client != nil {
+		request.Client = *client
+		GetHttp2SettingsfromClient(bot)
+	}
+	if ja3 != "" {
+		request.Ja3 = ja3
+	}
+	if profile != nil {
+		request.HTTP2TRANSPORT.ClientProfile = *profile
+	}
+	if pseudoorder != nil {
+		request.HTTP2TRANSPORT.ClientProfile.PseudoHeaderOrder = pseudoorder
+	}
+	if headerorder != nil {
+		request.HeaderOrderKey = headerorder
+	}
+	if proxy != "" {
+		request.Proxy = proxy
+	}
+	if timeout != "" {
+		request.Timeout = timeout
+	}
+	if redirects != "" {
+		request.MaxRedirects = redirects
+	}
+	return nil
+}
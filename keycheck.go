@@ -0,0 +1,212 @@
+package gotools
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	gostruct "github.com/kawacode/gostruct"
+)
+
+// Outcome is the result of a keycheck
+type Outcome int
+
+const (
+	OutcomeNone Outcome = iota
+	OutcomeSuccess
+	OutcomeFail
+	OutcomeRetry
+	OutcomeBan
+	OutcomeCustom
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "Success"
+	case OutcomeFail:
+		return "Fail"
+	case OutcomeRetry:
+		return "Retry"
+	case OutcomeBan:
+		return "Ban"
+	case OutcomeCustom:
+		return "Custom"
+	}
+	return "None"
+}
+
+// The parts of a bot a Condition can test
+const (
+	SubjectStatus   = "status"
+	SubjectSource   = "source"
+	SubjectURL      = "url"
+	SubjectHeader   = "header"
+	SubjectCookie   = "cookie"
+	SubjectVariable = "variable"
+)
+
+// The operators of a Condition. The numeric ones compare the subject and the value
+// as numbers.
+const (
+	OperatorContains     = "contains"
+	OperatorNotContains  = "notcontains"
+	OperatorEquals       = "equals"
+	OperatorNotEquals    = "notequals"
+	OperatorRegex        = "regex"
+	OperatorNotRegex     = "notregex"
+	OperatorExists       = "exists"
+	OperatorNotExists    = "notexists"
+	OperatorLess         = "<"
+	OperatorLessEqual    = "<="
+	OperatorGreater      = ">"
+	OperatorGreaterEqual = ">="
+)
+
+// Condition tests a part of a bot, like the status code equals 200 or the source
+// contains "Welcome". Name is the header, cookie or variable name for those
+// subjects. A condition with All or Any set is a group instead: All holds if every
+// condition in it holds, Any if at least one does.
+type Condition struct {
+	Subject         string
+	Name            string
+	Operator        string
+	Value           string
+	CaseInsensitive bool
+	All             []Condition
+	Any             []Condition
+}
+
+// KeycheckRule gives the outcome of a bot whose response meets the condition.
+// Custom names the outcome when it is OutcomeCustom.
+type KeycheckRule struct {
+	Condition Condition
+	Outcome   Outcome
+	Custom    string
+}
+
+// Keycheck decides the outcome of a bot from its response: the first rule whose
+// condition holds wins. Variables are the variables the variable subject tests,
+// nil if there are none.
+type Keycheck struct {
+	Rules     []KeycheckRule
+	Variables *Variables
+}
+
+// It returns the outcome of the first rule whose condition holds for the bot, and
+// its custom name for OutcomeCustom. It returns OutcomeNone if no rule holds, and
+// an error for a condition with an unknown subject or operator, an invalid regex
+// or a numeric comparison with a value that is not a number.
+func (k Keycheck) Check(bot *gostruct.BotData) (Outcome, string, error) {
+	for i, rule := range k.Rules {
+		holds, err := rule.Condition.Holds(bot, k.Variables)
+		if err != nil {
+			return OutcomeNone, "", fmt.Errorf("gotools: keycheck rule %d: %w", i, err)
+		}
+		if holds {
+			return rule.Outcome, rule.Custom, nil
+		}
+	}
+	return OutcomeNone, "", nil
+}
+
+// It reports whether the condition holds for the bot and its variables, which may be nil
+func (c Condition) Holds(bot *gostruct.BotData, variables *Variables) (bool, error) {
+	if len(c.All) > 0 || len(c.Any) > 0 {
+		for _, condition := range c.All {
+			if holds, err := condition.Holds(bot, variables); err != nil || !holds {
+				return false, err
+			}
+		}
+		if len(c.Any) == 0 {
+			return true, nil
+		}
+		for _, condition := range c.Any {
+			if holds, err := condition.Holds(bot, variables); err != nil || holds {
+				return holds, err
+			}
+		}
+		return false, nil
+	}
+	subject, exist, err := c.subject(bot, variables)
+	if err != nil {
+		return false, err
+	}
+	value := c.Value
+	if c.CaseInsensitive {
+		subject, value = strings.ToLower(subject), strings.ToLower(value)
+	}
+	switch c.Operator {
+	case OperatorExists:
+		return exist, nil
+	case OperatorNotExists:
+		return !exist, nil
+	case OperatorContains:
+		return exist && strings.Contains(subject, value), nil
+	case OperatorNotContains:
+		return !strings.Contains(subject, value), nil
+	case OperatorEquals:
+		return exist && subject == value, nil
+	case OperatorNotEquals:
+		return subject != value, nil
+	case OperatorRegex, OperatorNotRegex:
+		pattern := c.Value
+		if c.CaseInsensitive {
+			pattern = "(?i)" + pattern
+		}
+		expression, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, err
+		}
+		matches := exist && expression.MatchString(subject)
+		return matches == (c.Operator == OperatorRegex), nil
+	case OperatorLess, OperatorLessEqual, OperatorGreater, OperatorGreaterEqual:
+		if !exist {
+			return false, nil
+		}
+		left, err := strconv.ParseFloat(strings.TrimSpace(subject), 64)
+		if err != nil {
+			return false, nil
+		}
+		right, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return false, fmt.Errorf("%s %s: %q is not a number", c.Subject, c.Operator, c.Value)
+		}
+		switch c.Operator {
+		case OperatorLess:
+			return left < right, nil
+		case OperatorLessEqual:
+			return left <= right, nil
+		case OperatorGreater:
+			return left > right, nil
+		}
+		return left >= right, nil
+	}
+	return false, fmt.Errorf("unknown operator %q", c.Operator)
+}
+
+// It returns the part of the bot the condition tests and whether it is set
+func (c Condition) subject(bot *gostruct.BotData, variables *Variables) (string, bool, error) {
+	response := bot.HttpRequest.Response
+	switch c.Subject {
+	case SubjectStatus:
+		return strconv.Itoa(response.StatusCode), response.StatusCode != 0, nil
+	case SubjectSource:
+		return response.Source, true, nil
+	case SubjectURL:
+		return bot.HttpRequest.Request.URL, true, nil
+	case SubjectHeader:
+		value, exist := headerValue(response.Headers, c.Name)
+		return value, exist, nil
+	case SubjectCookie:
+		value, exist := response.Cookies[c.Name]
+		return value, exist, nil
+	case SubjectVariable:
+		if variables == nil {
+			return "", false, nil
+		}
+		return variables.resolve(c.Name)
+	}
+	return "", false, fmt.Errorf("unknown subject %q", c.Subject)
+}
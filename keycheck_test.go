@@ -0,0 +1,151 @@
+package gotools
+
+import (
+	"testing"
+
+	gostruct "github.com/kawacode/gostruct"
+)
+
+func keycheckBot() *gostruct.BotData {
+	bot := new(gostruct.BotData)
+	bot.HttpRequest.Request.URL = "https://example.com/login"
+	bot.HttpRequest.Response.StatusCode = 200
+	bot.HttpRequest.Response.Source = `Welcome back, {"balance": "12.50"}`
+	bot.HttpRequest.Response.Headers = map[string]string{"Content-Type": "text/html", "X-Remaining": "3"}
+	bot.HttpRequest.Response.Cookies = map[string]string{"session": "abc"}
+	return bot
+}
+
+func TestOutcomeString(t *testing.T) {
+	for outcome, want := range map[Outcome]string{
+		OutcomeNone:    "None",
+		OutcomeSuccess: "Success",
+		OutcomeFail:    "Fail",
+		OutcomeRetry:   "Retry",
+		OutcomeBan:     "Ban",
+		OutcomeCustom:  "Custom",
+		Outcome(99):    "None",
+	} {
+		if got := outcome.String(); got != want {
+			t.Errorf("Outcome(%d).String() = %q, want %q", outcome, got, want)
+		}
+	}
+}
+
+func TestConditionOperators(t *testing.T) {
+	variables := NewVariables()
+	variables.Set("user", "bob")
+	variables.SetList("codes", []string{"1", "2"})
+	tests := []struct {
+		condition Condition
+		want      bool
+	}{
+		{Condition{Subject: SubjectSource, Operator: OperatorContains, Value: "Welcome"}, true},
+		{Condition{Subject: SubjectSource, Operator: OperatorContains, Value: "welcome"}, false},
+		{Condition{Subject: SubjectSource, Operator: OperatorContains, Value: "welcome", CaseInsensitive: true}, true},
+		{Condition{Subject: SubjectSource, Operator: OperatorNotContains, Value: "Invalid"}, true},
+		{Condition{Subject: SubjectStatus, Operator: OperatorEquals, Value: "200"}, true},
+		{Condition{Subject: SubjectStatus, Operator: OperatorNotEquals, Value: "200"}, false},
+		{Condition{Subject: SubjectURL, Operator: OperatorRegex, Value: `/login$`}, true},
+		{Condition{Subject: SubjectURL, Operator: OperatorRegex, Value: `/LOGIN$`, CaseInsensitive: true}, true},
+		{Condition{Subject: SubjectURL, Operator: OperatorNotRegex, Value: `/logout`}, true},
+		{Condition{Subject: SubjectHeader, Name: "content-type", Operator: OperatorExists}, true},
+		{Condition{Subject: SubjectHeader, Name: "Location", Operator: OperatorNotExists}, true},
+		{Condition{Subject: SubjectCookie, Name: "session", Operator: OperatorEquals, Value: "abc"}, true},
+		{Condition{Subject: SubjectCookie, Name: "missing", Operator: OperatorEquals, Value: ""}, false},
+		{Condition{Subject: SubjectHeader, Name: "X-Remaining", Operator: OperatorLess, Value: "5"}, true},
+		{Condition{Subject: SubjectHeader, Name: "X-Remaining", Operator: OperatorLessEqual, Value: "3"}, true},
+		{Condition{Subject: SubjectHeader, Name: "X-Remaining", Operator: OperatorGreater, Value: "3"}, false},
+		{Condition{Subject: SubjectStatus, Operator: OperatorGreaterEqual, Value: "200"}, true},
+		{Condition{Subject: SubjectSource, Operator: OperatorGreater, Value: "1"}, false},
+		{Condition{Subject: SubjectHeader, Name: "Missing", Operator: OperatorLess, Value: "1"}, false},
+		{Condition{Subject: SubjectVariable, Name: "user", Operator: OperatorEquals, Value: "bob"}, true},
+		{Condition{Subject: SubjectVariable, Name: "codes[-1]", Operator: OperatorEquals, Value: "2"}, true},
+		{Condition{Subject: SubjectVariable, Name: "other", Operator: OperatorNotExists}, true},
+		{Condition{All: []Condition{
+			{Subject: SubjectStatus, Operator: OperatorEquals, Value: "200"},
+			{Subject: SubjectSource, Operator: OperatorContains, Value: "Welcome"},
+		}}, true},
+		{Condition{All: []Condition{
+			{Subject: SubjectStatus, Operator: OperatorEquals, Value: "200"},
+			{Subject: SubjectSource, Operator: OperatorContains, Value: "Invalid"},
+		}}, false},
+		{Condition{Any: []Condition{
+			{Subject: SubjectSource, Operator: OperatorContains, Value: "Invalid"},
+			{Subject: SubjectCookie, Name: "session", Operator: OperatorExists},
+		}}, true},
+		{Condition{
+			All: []Condition{{Subject: SubjectStatus, Operator: OperatorEquals, Value: "200"}},
+			Any: []Condition{{Subject: SubjectSource, Operator: OperatorContains, Value: "Invalid"}},
+		}, false},
+	}
+	bot := keycheckBot()
+	for _, test := range tests {
+		got, err := test.condition.Holds(bot, variables)
+		if err != nil {
+			t.Errorf("%+v: %v", test.condition, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%+v holds %v, want %v", test.condition, got, test.want)
+		}
+	}
+	if holds, err := (Condition{Subject: SubjectVariable, Name: "user", Operator: OperatorExists}).Holds(bot, nil); err != nil || holds {
+		t.Errorf("a variable condition without variables holds %v, %v, want false", holds, err)
+	}
+}
+
+func TestConditionErrors(t *testing.T) {
+	bot := keycheckBot()
+	for _, condition := range []Condition{
+		{Subject: "body", Operator: OperatorContains, Value: "x"},
+		{Subject: SubjectSource, Operator: "like", Value: "x"},
+		{Subject: SubjectSource, Operator: OperatorRegex, Value: "("},
+		{Subject: SubjectStatus, Operator: OperatorLess, Value: "many"},
+		{Any: []Condition{{Subject: "body", Operator: OperatorExists}}},
+	} {
+		if _, err := condition.Holds(bot, nil); err == nil {
+			t.Errorf("%+v returned no error", condition)
+		}
+	}
+}
+
+func TestKeycheck(t *testing.T) {
+	keycheck := Keycheck{Rules: []KeycheckRule{
+		{Condition: Condition{Subject: SubjectStatus, Operator: OperatorEquals, Value: "429"}, Outcome: OutcomeRetry},
+		{Condition: Condition{Subject: SubjectStatus, Operator: OperatorEquals, Value: "403"}, Outcome: OutcomeBan},
+		{Condition: Condition{Subject: SubjectSource, Operator: OperatorContains, Value: "Invalid"}, Outcome: OutcomeFail},
+		{Condition: Condition{Subject: SubjectSource, Operator: OperatorContains, Value: "2FA"}, Outcome: OutcomeCustom, Custom: "2fa"},
+		{Condition: Condition{Subject: SubjectVariable, Name: "expected", Operator: OperatorExists}, Outcome: OutcomeSuccess},
+	}}
+	tests := []struct {
+		status int
+		source string
+		want   Outcome
+		custom string
+	}{
+		{429, "", OutcomeRetry, ""},
+		{403, "", OutcomeBan, ""},
+		{200, "Invalid password", OutcomeFail, ""},
+		{200, "Enter your 2FA code", OutcomeCustom, "2fa"},
+		{200, "Welcome", OutcomeNone, ""},
+	}
+	for _, test := range tests {
+		bot := keycheckBot()
+		bot.HttpRequest.Response.StatusCode = test.status
+		bot.HttpRequest.Response.Source = test.source
+		outcome, custom, err := keycheck.Check(bot)
+		if err != nil || outcome != test.want || custom != test.custom {
+			t.Errorf("status %d %q: got %v %q %v, want %v %q", test.status, test.source, outcome, custom, err, test.want, test.custom)
+		}
+	}
+	keycheck.Variables = NewVariables()
+	keycheck.Variables.Set("expected", "yes")
+	if outcome, _, err := keycheck.Check(keycheckBot()); err != nil || outcome != OutcomeSuccess {
+		t.Errorf("with variables got %v %v, want Success", outcome, err)
+	}
+	keycheck.Rules = append([]KeycheckRule{{Condition: Condition{Subject: "body", Operator: OperatorExists}}}, keycheck.Rules...)
+	if _, _, err := keycheck.Check(keycheckBot()); err == nil {
+		t.Errorf("a rule with an unknown subject returned no error")
+	}
+}
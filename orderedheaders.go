@@ -0,0 +1,254 @@
+package gotools
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	fiber "github.com/gofiber/fiber/v2"
+	fhttp "github.com/kawacode/fhttp"
+	gostruct "github.com/kawacode/gostruct"
+)
+
+// HeaderField is a single header line with the name in its original case
+type HeaderField struct {
+	Name  string
+	Value string
+}
+
+// OrderedHeaders is a header list that keeps the insertion order, the original
+// case of the names and every value of repeated headers. Names are matched
+// case-insensitively.
+type OrderedHeaders []HeaderField
+
+// It appends a header line, keeping the values already set for the name
+func (h *OrderedHeaders) Add(name string, value string) {
+	*h = append(*h, HeaderField{Name: name, Value: value})
+}
+
+// It replaces every value of the header with a single one, kept at the position of
+// the first value, or appends it if the header is not set
+func (h *OrderedHeaders) Set(name string, value string) {
+	for i, field := range *h {
+		if strings.EqualFold(field.Name, name) {
+			(*h)[i] = HeaderField{Name: name, Value: value}
+			h.del(name, i+1)
+			return
+		}
+	}
+	h.Add(name, value)
+}
+
+// It removes every value of the header
+func (h *OrderedHeaders) Del(name string) {
+	h.del(name, 0)
+}
+
+// It removes every value of the header from the index on
+func (h *OrderedHeaders) del(name string, from int) {
+	fields := (*h)[:from]
+	for _, field := range (*h)[from:] {
+		if !strings.EqualFold(field.Name, name) {
+			fields = append(fields, field)
+		}
+	}
+	*h = fields
+}
+
+// It returns the first value of the header, or "" if it is not set
+func (h OrderedHeaders) Get(name string) string {
+	for _, field := range h {
+		if strings.EqualFold(field.Name, name) {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+// It returns every value of the header in order
+func (h OrderedHeaders) Values(name string) []string {
+	var values []string
+	for _, field := range h {
+		if strings.EqualFold(field.Name, name) {
+			values = append(values, field.Value)
+		}
+	}
+	return values
+}
+
+// It returns the header names in the order they were first added, each name once
+func (h OrderedHeaders) Names() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, field := range h {
+		if !seen[strings.ToLower(field.Name)] {
+			seen[strings.ToLower(field.Name)] = true
+			names = append(names, field.Name)
+		}
+	}
+	return names
+}
+
+// It joins the values of a repeated header into one value. Set-Cookie lines cannot
+// be combined into one line, so they are joined with "\n", which no header value contains.
+func joinHeaderValues(name string, values []string) string {
+	name = strings.TrimSpace(name)
+	switch {
+	case strings.EqualFold(name, "Set-Cookie"):
+		return strings.Join(values, "\n")
+	case strings.EqualFold(name, "Cookie"):
+		return strings.Join(values, "; ")
+	}
+	return strings.Join(values, ", ")
+}
+
+// It splits a value joined by MapStringSliceToMapStringLossless back into the
+// lines it must be sent as. List values joined with ", " are valid as one line
+// and are not split.
+func SplitHeaderValues(value string) []string {
+	return strings.Split(value, "\n")
+}
+
+// It parses a Set-Cookie line into a fiber cookie, keeping its attributes
+func parseSetCookie(line string) (*fiber.Cookie, bool) {
+	cookies := (&http.Response{Header: http.Header{"Set-Cookie": {line}}}).Cookies()
+	if len(cookies) == 0 {
+		return nil, false
+	}
+	cookie := &fiber.Cookie{
+		Name:     cookies[0].Name,
+		Value:    cookies[0].Value,
+		Path:     cookies[0].Path,
+		Domain:   cookies[0].Domain,
+		MaxAge:   cookies[0].MaxAge,
+		Expires:  cookies[0].Expires,
+		Secure:   cookies[0].Secure,
+		HTTPOnly: cookies[0].HttpOnly,
+	}
+	switch cookies[0].SameSite {
+	case http.SameSiteStrictMode:
+		cookie.SameSite = fiber.CookieSameSiteStrictMode
+	case http.SameSiteNoneMode:
+		cookie.SameSite = fiber.CookieSameSiteNoneMode
+	case http.SameSiteLaxMode:
+		cookie.SameSite = fiber.CookieSameSiteLaxMode
+	default:
+		cookie.SameSite = fiber.CookieSameSiteDisabled
+	}
+	return cookie, true
+}
+
+// It converts the headers to the map used by BotData, joining repeated headers into one value
+func (h OrderedHeaders) Map() map[string]string {
+	result := make(map[string]string)
+	for _, name := range h.Names() {
+		result[name] = joinHeaderValues(name, h.Values(name))
+	}
+	return result
+}
+
+// It converts the headers to a net/http Header. The order is lost, the values are kept.
+func (h OrderedHeaders) Header() http.Header {
+	result := make(http.Header)
+	for _, field := range h {
+		result.Add(field.Name, field.Value)
+	}
+	return result
+}
+
+// It converts the headers to fhttp headers for the bot with every value, in their
+// original case for HTTP/1.1 and lowercased for HTTP/2, plus the "Header-Order:"
+// and "PHeader-Order:" keys that keep their order on the wire. Headers are dropped
+// like MapStringToMapStringSlice does.
+func (h OrderedHeaders) Fhttp(bot *gostruct.BotData) fhttp.Header {
+	result := make(fhttp.Header)
+	filter := newSendFilter(h.Values("Connection"), bot.HttpRequest.Request.Protocol)
+	var names []string
+	for _, name := range h.Names() {
+		for _, value := range h.Values(name) {
+			if filter.keep(name, value) {
+				result[filter.name(name)] = append(result[filter.name(name)], value)
+			}
+		}
+		if len(result[filter.name(name)]) > 0 {
+			names = append(names, name)
+		}
+	}
+	setHeaderOrderKeys(result, names, names, bot)
+	return result
+}
+
+// It sets the bot's request headers and HeaderOrderKey from the headers
+func (h OrderedHeaders) SetToBot(bot *gostruct.BotData) {
+	bot.HttpRequest.Request.Headers = h.Map()
+	bot.HttpRequest.Request.HeaderOrderKey = h.Names()
+}
+
+// It builds ordered headers from a net/http Header. A Header has no order, so the
+// names are sorted.
+func OrderedHeadersFromHeader(header http.Header) OrderedHeaders {
+	var names []string
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var result OrderedHeaders
+	for _, name := range names {
+		for _, value := range header[name] {
+			result.Add(name, value)
+		}
+	}
+	return result
+}
+
+// It builds ordered headers from fhttp headers, in the order of their "Header-Order:"
+// key, followed by the headers it does not list sorted by name
+func OrderedHeadersFromFhttp(header fhttp.Header) OrderedHeaders {
+	var names []string
+	for name := range header {
+		if name != "Header-Order:" && name != "PHeader-Order:" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	position := make(map[string]int)
+	for i, key := range header["Header-Order:"] {
+		if _, exist := position[strings.ToLower(key)]; !exist {
+			position[strings.ToLower(key)] = i
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		a, aordered := position[strings.ToLower(names[i])]
+		b, bordered := position[strings.ToLower(names[j])]
+		if aordered && bordered {
+			return a < b
+		}
+		return aordered && !bordered
+	})
+	var result OrderedHeaders
+	for _, name := range names {
+		for _, value := range header[name] {
+			result.Add(name, value)
+		}
+	}
+	return result
+}
+
+// It builds ordered headers from the bot's request headers, ordered like
+// MapStringToMapStringSlice orders them
+func OrderedHeadersFromBot(bot *gostruct.BotData) OrderedHeaders {
+	var names []string
+	for name := range bot.HttpRequest.Request.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var result OrderedHeaders
+	for _, key := range MergeHeaderOrder(bot.HttpRequest.Request.HeaderOrderKey, GetDefaultHeaderOrder(bot), names) {
+		for _, name := range names {
+			if strings.EqualFold(name, key) {
+				result.Add(name, bot.HttpRequest.Request.Headers[name])
+			}
+		}
+	}
+	return result
+}
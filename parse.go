@@ -0,0 +1,301 @@
+package gotools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	cascadia "github.com/andybalholm/cascadia"
+	html "golang.org/x/net/html"
+)
+
+// It returns the pattern matching the text between left and right. An empty left
+// matches from the start of the source and an empty right up to its end.
+func lrPattern(left string, right string, caseinsensitive bool) *regexp.Regexp {
+	flags := "(?s)"
+	if caseinsensitive {
+		flags = "(?is)"
+	}
+	pattern := flags + regexp.QuoteMeta(left) + "(.*?)" + regexp.QuoteMeta(right)
+	if left == "" {
+		pattern = flags + `\A(.*?)` + regexp.QuoteMeta(right)
+	}
+	if right == "" {
+		pattern = strings.Replace(pattern, "(.*?)", `(.*)\z`, 1)
+	}
+	return regexp.MustCompile(pattern)
+}
+
+// It returns the text between the first left and the right that follows it, and
+// false if there is none. An empty left matches from the start of the source and
+// an empty right up to its end.
+func ParseLR(source string, left string, right string, caseinsensitive bool) (string, bool) {
+	match := lrPattern(left, right, caseinsensitive).FindStringSubmatch(source)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// It returns the text between every left and the right that follows it, nil if
+// there is none
+func ParseLRAll(source string, left string, right string, caseinsensitive bool) []string {
+	var result []string
+	for _, match := range lrPattern(left, right, caseinsensitive).FindAllStringSubmatch(source, -1) {
+		result = append(result, match[1])
+	}
+	return result
+}
+
+// It returns the first match of pattern expanded with template, where $1 or ${name}
+// is a capture group and an empty template is the whole match. It returns false
+// if nothing matches and an error if pattern is not a valid regular expression.
+func ParseRegex(source string, pattern string, template string) (string, bool, error) {
+	result, err := parseRegex(source, pattern, template, 1)
+	if err != nil || len(result) == 0 {
+		return "", false, err
+	}
+	return result[0], true, nil
+}
+
+// It returns every match of pattern expanded with template, see ParseRegex
+func ParseRegexAll(source string, pattern string, template string) ([]string, error) {
+	return parseRegex(source, pattern, template, -1)
+}
+
+func parseRegex(source string, pattern string, template string, n int) ([]string, error) {
+	expression, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("gotools: parse regex: %w", err)
+	}
+	if template == "" {
+		template = "$0"
+	}
+	var result []string
+	for _, match := range expression.FindAllStringSubmatchIndex(source, n) {
+		result = append(result, string(expression.ExpandString(nil, template, source, match)))
+	}
+	return result, nil
+}
+
+// jsonPathStep is a key, an index or every item of a JSON path
+type jsonPathStep struct {
+	key   string
+	index int
+	isKey bool
+	all   bool
+}
+
+// It splits a path like `data.items[0].name`, `["a.b"]` or `items[*].id` into steps
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	var steps []jsonPathStep
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+		case '[':
+			if i+1 < len(path) && (path[i+1] == '"' || path[i+1] == '\'') {
+				// a quoted key may contain dots and brackets
+				closing := strings.IndexByte(path[i+2:], path[i+1])
+				if closing == -1 || i+2+closing+1 >= len(path) || path[i+2+closing+1] != ']' {
+					return nil, fmt.Errorf("gotools: parse json: unclosed quoted key in path %q", path)
+				}
+				steps = append(steps, jsonPathStep{key: path[i+2 : i+2+closing], isKey: true})
+				i += closing + 4
+				continue
+			}
+			end := strings.IndexByte(path[i:], ']')
+			if end == -1 {
+				return nil, fmt.Errorf("gotools: parse json: unclosed [ in path %q", path)
+			}
+			inner := strings.TrimSpace(path[i+1 : i+end])
+			if inner == "*" {
+				steps = append(steps, jsonPathStep{all: true})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("gotools: parse json: invalid index %q in path %q", inner, path)
+				}
+				steps = append(steps, jsonPathStep{index: index})
+			}
+			i += end + 1
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end == -1 {
+				end = len(path) - i
+			}
+			steps = append(steps, jsonPathStep{key: path[i : i+end], isKey: true})
+			i += end
+		}
+	}
+	return steps, nil
+}
+
+// It returns the values the steps lead to from value
+func walkJSON(value interface{}, steps []jsonPathStep) []interface{} {
+	if len(steps) == 0 {
+		return []interface{}{value}
+	}
+	step := steps[0]
+	var result []interface{}
+	switch node := value.(type) {
+	case map[string]interface{}:
+		if step.all {
+			for _, key := range sortedInterfaceKeys(node) {
+				result = append(result, walkJSON(node[key], steps[1:])...)
+			}
+		} else if child, exist := node[step.key]; exist && step.isKey {
+			result = walkJSON(child, steps[1:])
+		}
+	case []interface{}:
+		switch {
+		case step.all:
+			for _, child := range node {
+				result = append(result, walkJSON(child, steps[1:])...)
+			}
+		case step.isKey:
+			// arrays have no keys
+		case step.index < 0 && -step.index <= len(node):
+			result = walkJSON(node[len(node)+step.index], steps[1:])
+		case step.index >= 0 && step.index < len(node):
+			result = walkJSON(node[step.index], steps[1:])
+		}
+	}
+	return result
+}
+
+// It returns the keys of a JSON object in sorted order
+func sortedInterfaceKeys(node map[string]interface{}) []string {
+	var keys []string
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// It returns a JSON value as text: strings without quotes, null as an empty string
+// and numbers, booleans, objects and arrays as JSON
+func jsonValueString(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	}
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+// It returns the value at a path like `data.items[0].name` in a JSON document. Keys
+// with dots go in brackets like `["a.b"]`, negative indexes count from the end and
+// [*] is every item. Strings are returned without quotes, objects and arrays as
+// JSON. It returns false if the path leads nowhere and an error if the source is
+// not JSON or the path is invalid.
+func ParseJSON(source string, path string) (string, bool, error) {
+	result, err := ParseJSONAll(source, path)
+	if err != nil || len(result) == 0 {
+		return "", false, err
+	}
+	return result[0], true, nil
+}
+
+// It returns every value at a path in a JSON document, see ParseJSON
+func ParseJSONAll(source string, path string) ([]string, error) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(strings.NewReader(source))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("gotools: parse json: %w", err)
+	}
+	var result []string
+	for _, value := range walkJSON(document, steps) {
+		result = append(result, jsonValueString(value))
+	}
+	return result, nil
+}
+
+// It returns what attribute selects of an element: "innerText" or an empty attribute
+// for its text, "innerHTML" and "outerHTML" for its markup, otherwise the value of
+// the attribute. It returns false if the element has no such attribute.
+func cssAttribute(node *html.Node, attribute string) (string, bool) {
+	switch attribute {
+	case "", "innerText", "text":
+		var text strings.Builder
+		var walk func(*html.Node)
+		walk = func(n *html.Node) {
+			if n.Type == html.TextNode {
+				text.WriteString(n.Data)
+			}
+			for child := n.FirstChild; child != nil; child = child.NextSibling {
+				walk(child)
+			}
+		}
+		walk(node)
+		return strings.TrimSpace(text.String()), true
+	case "innerHTML":
+		var markup bytes.Buffer
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			html.Render(&markup, child)
+		}
+		return markup.String(), true
+	case "outerHTML":
+		var markup bytes.Buffer
+		html.Render(&markup, node)
+		return markup.String(), true
+	}
+	for _, attr := range node.Attr {
+		if strings.EqualFold(attr.Key, attribute) {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// It returns attribute of the first element matching a CSS selector, like the href
+// of "a.next" or the value of `input[name="csrf"]`. attribute is an attribute name,
+// "innerText", "innerHTML" or "outerHTML", an empty attribute is the text. It
+// returns false if no element has it and an error if the selector is invalid.
+func ParseCSS(source string, selector string, attribute string) (string, bool, error) {
+	result, err := parseCSS(source, selector, attribute, true)
+	if err != nil || len(result) == 0 {
+		return "", false, err
+	}
+	return result[0], true, nil
+}
+
+// It returns attribute of every element matching a CSS selector, see ParseCSS
+func ParseCSSAll(source string, selector string, attribute string) ([]string, error) {
+	return parseCSS(source, selector, attribute, false)
+}
+
+func parseCSS(source string, selector string, attribute string, first bool) ([]string, error) {
+	compiled, err := cascadia.Compile(selector)
+	if err != nil {
+		return nil, fmt.Errorf("gotools: parse css: %w", err)
+	}
+	document, err := html.Parse(strings.NewReader(source))
+	if err != nil {
+		return nil, fmt.Errorf("gotools: parse css: %w", err)
+	}
+	var result []string
+	for _, node := range compiled.MatchAll(document) {
+		if value, exist := cssAttribute(node, attribute); exist {
+			result = append(result, value)
+			if first {
+				break
+			}
+		}
+	}
+	return result, nil
+}
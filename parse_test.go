@@ -0,0 +1,112 @@
+package gotools
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLR(t *testing.T) {
+	source := "token=abc; Path=/; token=def; end"
+	tests := []struct {
+		left, right     string
+		caseinsensitive bool
+		want            string
+		found           bool
+	}{
+		{"token=", ";", false, "abc", true},
+		{"TOKEN=", ";", true, "abc", true},
+		{"TOKEN=", ";", false, "", false},
+		{"", ";", false, "token=abc", true},
+		{"end", "", false, "", true},
+		{"Path=", "", false, "/; token=def; end", true},
+		{"", "", false, source, true},
+		{"missing", ";", false, "", false},
+	}
+	for _, test := range tests {
+		got, found := ParseLR(source, test.left, test.right, test.caseinsensitive)
+		if got != test.want || found != test.found {
+			t.Errorf("ParseLR(%q, %q) = %q, %v, want %q, %v", test.left, test.right, got, found, test.want, test.found)
+		}
+	}
+	if got := ParseLRAll(source, "token=", ";", false); !reflect.DeepEqual(got, []string{"abc", "def"}) {
+		t.Errorf("ParseLRAll(token=, ;) = %q", got)
+	}
+	if got := ParseLRAll("a\nb", "", "", false); !reflect.DeepEqual(got, []string{"a\nb"}) {
+		t.Errorf("ParseLRAll with empty delimiters = %q, want the whole source once", got)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	source := `{"data": {"items": [{"id": 1, "name": "a"}, {"id": 2, "name": "b", "tags": ["x"]}], "a.b": true, "empty": null}}`
+	tests := []struct {
+		path  string
+		want  []string
+		found bool
+	}{
+		{"data.items[0].name", []string{"a"}, true},
+		{"data.items[-1].id", []string{"2"}, true},
+		{"data.items[*].id", []string{"1", "2"}, true},
+		{"data.items[*].tags", []string{`["x"]`}, true},
+		{`data["a.b"]`, []string{"true"}, true},
+		{`data['a.b']`, []string{"true"}, true},
+		{"data.empty", []string{""}, true},
+		{"data.items[1]", []string{`{"id":2,"name":"b","tags":["x"]}`}, true},
+		{"data.*", nil, false},
+		{"data.missing", nil, false},
+		{"data.items[5]", nil, false},
+		{"data.items[-3]", nil, false},
+		{"data.items.name", nil, false},
+	}
+	for _, test := range tests {
+		got, err := ParseJSONAll(source, test.path)
+		if err != nil {
+			t.Errorf("ParseJSONAll(%q): %v", test.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseJSONAll(%q) = %q, want %q", test.path, got, test.want)
+		}
+		first, found, _ := ParseJSON(source, test.path)
+		if found != test.found || found && first != test.want[0] {
+			t.Errorf("ParseJSON(%q) = %q, %v, want found %v", test.path, first, found, test.found)
+		}
+	}
+	for _, path := range []string{"data[", "data[x]", `data["a.b]`} {
+		if _, err := ParseJSONAll(source, path); err == nil {
+			t.Errorf("ParseJSONAll(%q) returned no error", path)
+		}
+	}
+	if _, err := ParseJSONAll("{", "a"); err == nil {
+		t.Errorf("ParseJSONAll of invalid JSON returned no error")
+	}
+}
+
+func TestParseCSS(t *testing.T) {
+	source := `<html><body><a class="next" href="/2">Next <b>page</b></a><a class="next" href="/3">Last</a>
+		<input name="csrf" value="tok"></body></html>`
+	tests := []struct {
+		selector, attribute string
+		want                string
+		found               bool
+	}{
+		{"a.next", "href", "/2", true},
+		{"a.next", "", "Next page", true},
+		{"a.next", "innerHTML", "Next <b>page</b>", true},
+		{`input[name="csrf"]`, "value", "tok", true},
+		{`input[name="csrf"]`, "outerHTML", `<input name="csrf" value="tok"/>`, true},
+		{"a.next", "title", "", false},
+		{"form", "", "", false},
+	}
+	for _, test := range tests {
+		got, found, err := ParseCSS(source, test.selector, test.attribute)
+		if err != nil || got != test.want || found != test.found {
+			t.Errorf("ParseCSS(%q, %q) = %q, %v, %v, want %q, %v", test.selector, test.attribute, got, found, err, test.want, test.found)
+		}
+	}
+	if got, _ := ParseCSSAll(source, "a.next", "href"); !reflect.DeepEqual(got, []string{"/2", "/3"}) {
+		t.Errorf("ParseCSSAll(a.next, href) = %q", got)
+	}
+	if _, _, err := ParseCSS(source, "a[", ""); err == nil {
+		t.Errorf("ParseCSS with an invalid selector returned no error")
+	}
+}
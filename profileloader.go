@@ -0,0 +1,431 @@
+package gotools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	http2 "github.com/kawacode/fhttp/http2"
+	gostruct "github.com/kawacode/gostruct"
+	tls "github.com/kawacode/utls"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// BrowserProfile is a complete client fingerprint: the TLS ClientHello as JA3, the
+// HTTP/2 profile and the default headers with their order
+type BrowserProfile struct {
+	Name        string
+	Client      tls.ClientHelloID
+	Ja3         string
+	Protocol    string
+	Http2       ClientProfile
+	Headers     map[string]string
+	HeaderOrder []string
+}
+
+// profileFile is the on-disk format of a BrowserProfile, as JSON or YAML
+type profileFile struct {
+	Name     string `json:"name" yaml:"name"`
+	Client   string `json:"client" yaml:"client"`
+	Version  string `json:"version" yaml:"version"`
+	Ja3      string `json:"ja3" yaml:"ja3"`
+	Protocol string `json:"protocol" yaml:"protocol"`
+	Http2    struct {
+		Settings          map[string]uint32 `json:"settings" yaml:"settings"`
+		SettingsOrder     []string          `json:"settings_order" yaml:"settings_order"`
+		PseudoHeaderOrder []string          `json:"pseudo_header_order" yaml:"pseudo_header_order"`
+		ConnectionFlow    uint32            `json:"connection_flow" yaml:"connection_flow"`
+		Priorities        []struct {
+			StreamID  uint32 `json:"stream_id" yaml:"stream_id"`
+			StreamDep uint32 `json:"stream_dep" yaml:"stream_dep"`
+			Exclusive bool   `json:"exclusive" yaml:"exclusive"`
+			Weight    uint8  `json:"weight" yaml:"weight"`
+		} `json:"priorities" yaml:"priorities"`
+	} `json:"http2" yaml:"http2"`
+	Headers     map[string]string `json:"headers" yaml:"headers"`
+	HeaderOrder []string          `json:"header_order" yaml:"header_order"`
+}
+
+var (
+	browserProfilesMu sync.RWMutex
+	browserProfiles   = make(map[string]*BrowserProfile)
+	helloClients      = make(map[string]*tls.ClientHelloID)
+	// replacedHttp2Profiles keeps the HTTP/2 profiles RegisterBrowserProfile replaced
+	replacedHttp2Profiles = make(map[string]ClientProfile)
+)
+
+// It converts a SETTINGS name like "HEADER_TABLE_SIZE", "SETTINGS_HEADER_TABLE_SIZE" or "1" to its id
+func parseSettingID(name string) (http2.SettingID, error) {
+	switch strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(name)), "SETTINGS_") {
+	case "HEADER_TABLE_SIZE":
+		return http2.SettingHeaderTableSize, nil
+	case "ENABLE_PUSH":
+		return http2.SettingEnablePush, nil
+	case "MAX_CONCURRENT_STREAMS":
+		return http2.SettingMaxConcurrentStreams, nil
+	case "INITIAL_WINDOW_SIZE":
+		return http2.SettingInitialWindowSize, nil
+	case "MAX_FRAME_SIZE":
+		return http2.SettingMaxFrameSize, nil
+	case "MAX_HEADER_LIST_SIZE":
+		return http2.SettingMaxHeaderListSize, nil
+	}
+	id, err := strconv.ParseUint(strings.TrimSpace(name), 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("unknown http2 setting %q", name)
+	}
+	return http2.SettingID(id), nil
+}
+
+// It parses a JSON or YAML profile, chosen by the file extension, into a validated BrowserProfile
+func ParseBrowserProfile(data []byte, ext string) (*BrowserProfile, error) {
+	var file profileFile
+	switch strings.ToLower(ext) {
+	case ".json":
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, err
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported profile format %q", ext)
+	}
+	if file.Client == "" || file.Version == "" {
+		return nil, fmt.Errorf("profile needs a client and a version")
+	}
+	profile := &BrowserProfile{
+		Name:        file.Name,
+		Client:      tls.ClientHelloID{Client: file.Client, Version: file.Version},
+		Ja3:         file.Ja3,
+		Protocol:    file.Protocol,
+		Headers:     file.Headers,
+		HeaderOrder: file.HeaderOrder,
+	}
+	if profile.Name == "" {
+		profile.Name = profile.Client.Str()
+	}
+	if profile.Protocol == "" {
+		profile.Protocol = "2"
+	}
+	if profile.Ja3 != "" {
+		if _, err := ParseJA3(profile.Ja3, profile.Protocol); err != nil {
+			return nil, fmt.Errorf("invalid ja3: %w", err)
+		}
+		ja3, protocol := profile.Ja3, profile.Protocol
+		profile.Client.SpecFactory = func() (tls.ClientHelloSpec, error) {
+			spec, err := ParseJA3(ja3, protocol)
+			if err != nil {
+				return tls.ClientHelloSpec{}, err
+			}
+			return *spec, nil
+		}
+	} else {
+		profile.Client.SpecFactory = tls.EmptyClientHelloSpecFactory
+	}
+	profile.Http2.Settings = make(map[http2.SettingID]uint32)
+	for name, value := range file.Http2.Settings {
+		id, err := parseSettingID(name)
+		if err != nil {
+			return nil, err
+		}
+		profile.Http2.Settings[id] = value
+	}
+	for _, name := range file.Http2.SettingsOrder {
+		id, err := parseSettingID(name)
+		if err != nil {
+			return nil, err
+		}
+		profile.Http2.SettingsOrder = append(profile.Http2.SettingsOrder, id)
+	}
+	profile.Http2.PseudoHeaderOrder = file.Http2.PseudoHeaderOrder
+	profile.Http2.ConnectionFlow = file.Http2.ConnectionFlow
+	for _, priority := range file.Http2.Priorities {
+		profile.Http2.Priorities = append(profile.Http2.Priorities, http2.Priority{
+			StreamID: priority.StreamID,
+			PriorityParam: http2.PriorityParam{
+				StreamDep: priority.StreamDep,
+				Exclusive: priority.Exclusive,
+				Weight:    priority.Weight,
+			},
+		})
+	}
+	if err := ValidateHttp2Profile(profile.Http2); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// It reads and parses a single profile file
+func LoadProfileFile(path string) (*BrowserProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := ParseBrowserProfile(data, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("gotools: profile %s: %w", path, err)
+	}
+	return profile, nil
+}
+
+// It returns the name GetHelloClient resolves to the client, like "HelloChrome_120"
+func helloClientName(client tls.ClientHelloID) string {
+	return strings.ToUpper("Hello" + client.Client + "_" + strings.ReplaceAll(client.Version, ".", "_"))
+}
+
+// It registers the profile next to the built-in ones: its HTTP/2 profile under
+// Client.Str(), and its ClientHelloID under its name and "Hello<Client>_<Version>"
+// so GetHelloClient and GetHttp2SettingsfromClient find it. An HTTP/2 profile it
+// replaces is kept and restored by UnregisterBrowserProfile, a loaded profile it
+// replaces loses its names.
+func RegisterBrowserProfile(profile *BrowserProfile) error {
+	name := profile.Client.Str()
+	browserProfilesMu.Lock()
+	defer browserProfilesMu.Unlock()
+	replaced, exist := GetHttp2Profile(name)
+	if err := RegisterHttp2Profile(name, profile.Http2); err != nil {
+		return err
+	}
+	if previous, loaded := browserProfiles[name]; loaded {
+		deleteHelloClients(previous)
+	} else if exist {
+		replacedHttp2Profiles[name] = replaced
+	}
+	browserProfiles[name] = profile
+	helloClients[helloClientName(profile.Client)] = &profile.Client
+	helloClients[strings.ToUpper(profile.Name)] = &profile.Client
+	return nil
+}
+
+// It removes a profile registered with RegisterBrowserProfile, restoring the
+// HTTP/2 profile it replaced, like a built-in one. A profile that was replaced by
+// an other one for the same client leaves that one registered.
+func UnregisterBrowserProfile(profile *BrowserProfile) {
+	name := profile.Client.Str()
+	browserProfilesMu.Lock()
+	defer browserProfilesMu.Unlock()
+	deleteHelloClients(profile)
+	if browserProfiles[name] != profile {
+		return
+	}
+	tlsClientsMu.Lock()
+	if replaced, exist := replacedHttp2Profiles[name]; exist {
+		tlsClients[name] = replaced
+		delete(replacedHttp2Profiles, name)
+	} else {
+		delete(tlsClients, name)
+	}
+	tlsClientsMu.Unlock()
+	delete(browserProfiles, name)
+}
+
+// It removes the names registered for the profile's ClientHelloID
+func deleteHelloClients(profile *BrowserProfile) {
+	for name, id := range helloClients {
+		if id == &profile.Client {
+			delete(helloClients, name)
+		}
+	}
+}
+
+// It returns the browser profile registered for a tls.ClientHelloID.Str()
+func GetBrowserProfile(name string) (*BrowserProfile, bool) {
+	browserProfilesMu.RLock()
+	defer browserProfilesMu.RUnlock()
+	profile, exist := browserProfiles[name]
+	return profile, exist
+}
+
+// It returns a ClientHelloID registered by a loaded profile
+func getLoadedHelloClient(client string) (*tls.ClientHelloID, bool) {
+	browserProfilesMu.RLock()
+	defer browserProfilesMu.RUnlock()
+	id, exist := helloClients[strings.ToUpper(client)]
+	return id, exist
+}
+
+// It configures the bot to use the profile: client, JA3, protocol, HTTP/2 profile,
+// and the default headers and header order where the bot has none set
+func (profile *BrowserProfile) Apply(bot *gostruct.BotData) {
+	bot.HttpRequest.Request.Client = profile.Client
+	bot.HttpRequest.Request.Ja3 = profile.Ja3
+	bot.HttpRequest.Request.Protocol = profile.Protocol
+	bot.HttpRequest.Request.HTTP2TRANSPORT.ClientProfile = copyClientProfile(profile.Http2)
+	if bot.HttpRequest.Request.Headers == nil {
+		bot.HttpRequest.Request.Headers = make(map[string]string)
+	}
+	for k, v := range profile.Headers {
+		if _, exist := bot.HttpRequest.Request.Headers[k]; !exist {
+			bot.HttpRequest.Request.Headers[k] = v
+		}
+	}
+	if len(bot.HttpRequest.Request.HeaderOrderKey) == 0 {
+		bot.HttpRequest.Request.HeaderOrderKey = append([]string(nil), profile.HeaderOrder...)
+	}
+}
+
+// isProfileFile reports whether the file name has a profile extension
+func isProfileFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// ProfileDirError lists the profile files of a directory that failed to load
+type ProfileDirError struct {
+	Errors []error
+}
+
+func (e *ProfileDirError) Error() string {
+	var messages []string
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// It loads and registers every .json, .yaml and .yml profile in the directory.
+// Files that fail to load are skipped and reported in a *ProfileDirError, the
+// others are still registered.
+func LoadProfileDir(dir string) ([]*BrowserProfile, error) {
+	loaded, _, err := loadProfileDir(dir)
+	var names []string
+	for name := range loaded {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var profiles []*BrowserProfile
+	for _, name := range names {
+		profiles = append(profiles, loaded[name])
+	}
+	return profiles, err
+}
+
+// It loads and registers the profiles of the directory like LoadProfileDir, and
+// returns them by file name with the names of the files that failed to load
+func loadProfileDir(dir string) (map[string]*BrowserProfile, map[string]bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		loaded = make(map[string]*BrowserProfile)
+		failed = make(map[string]bool)
+		errs   []error
+	)
+	for _, entry := range entries {
+		if entry.IsDir() || !isProfileFile(entry.Name()) {
+			continue
+		}
+		profile, err := LoadProfileFile(filepath.Join(dir, entry.Name()))
+		if err == nil {
+			err = RegisterBrowserProfile(profile)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			failed[entry.Name()] = true
+			continue
+		}
+		loaded[entry.Name()] = profile
+	}
+	if len(errs) > 0 {
+		return loaded, failed, &ProfileDirError{Errors: errs}
+	}
+	return loaded, failed, nil
+}
+
+// It returns a fingerprint of the profile files in the directory that changes
+// whenever a file is added, removed or modified
+func profileDirState(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var state []string
+	for _, entry := range entries {
+		if entry.IsDir() || !isProfileFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return "", err
+		}
+		state = append(state, fmt.Sprintf("%s:%d:%d", entry.Name(), info.Size(), info.ModTime().UnixNano()))
+	}
+	sort.Strings(state)
+	return strings.Join(state, "|"), nil
+}
+
+// It loads the profile directory and keeps reloading it every interval while files
+// change, until ctx is done. Profiles whose file was removed are unregistered, a
+// file that fails to load, like a half written one, keeps its last good profile.
+// Load errors are passed to onerror, which may be nil.
+func WatchProfileDir(ctx context.Context, dir string, interval time.Duration, onerror func(error)) error {
+	if interval <= 0 {
+		return fmt.Errorf("gotools: profile watch interval must be positive, got %s", interval)
+	}
+	state, err := profileDirState(dir)
+	if err != nil {
+		return err
+	}
+	loaded, _, err := loadProfileDir(dir)
+	if err != nil && onerror != nil {
+		onerror(err)
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			current, err := profileDirState(dir)
+			if err != nil {
+				if onerror != nil {
+					onerror(err)
+				}
+				continue
+			}
+			if current == state {
+				continue
+			}
+			reloaded, failed, err := loadProfileDir(dir)
+			if err != nil && onerror != nil {
+				onerror(err)
+			}
+			if reloaded == nil {
+				continue
+			}
+			state = current
+			for file, profile := range loaded {
+				if _, exist := reloaded[file]; !exist && failed[file] {
+					reloaded[file] = profile
+				}
+			}
+			kept := make(map[string]bool)
+			for _, profile := range reloaded {
+				kept[profile.Client.Str()] = true
+			}
+			for _, profile := range loaded {
+				if !kept[profile.Client.Str()] {
+					UnregisterBrowserProfile(profile)
+				}
+			}
+			loaded = reloaded
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,784 @@
+package gotools
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	http2 "github.com/kawacode/fhttp/http2"
+	tls "github.com/kawacode/utls"
+)
+
+// ClientProfile is the HTTP/2 fingerprint of a client: its SETTINGS frame, the
+// connection WINDOW_UPDATE, the pseudo header order and the PRIORITY frames
+type ClientProfile struct {
+	Settings          map[http2.SettingID]uint32
+	SettingsOrder     []http2.SettingID
+	PseudoHeaderOrder []string
+	ConnectionFlow    uint32
+	Priorities        []http2.Priority
+}
+
+var chrome_106 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:      65536,
+		http2.SettingEnablePush:           0,
+		http2.SettingMaxConcurrentStreams: 1000,
+		http2.SettingInitialWindowSize:    6291456,
+		http2.SettingMaxHeaderListSize:    262144,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingEnablePush,
+		http2.SettingMaxConcurrentStreams,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxHeaderListSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":authority",
+		":scheme",
+		":path",
+	},
+	ConnectionFlow: 15663105,
+}
+
+var chrome_105 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:      65536,
+		http2.SettingMaxConcurrentStreams: 1000,
+		http2.SettingInitialWindowSize:    6291456,
+		http2.SettingMaxHeaderListSize:    262144,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingMaxConcurrentStreams,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxHeaderListSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":authority",
+		":scheme",
+		":path",
+	},
+	ConnectionFlow: 15663105,
+}
+
+var chrome_104 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:      65536,
+		http2.SettingMaxConcurrentStreams: 1000,
+		http2.SettingInitialWindowSize:    6291456,
+		http2.SettingMaxHeaderListSize:    262144,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingMaxConcurrentStreams,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxHeaderListSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":authority",
+		":scheme",
+		":path",
+	},
+	ConnectionFlow: 15663105,
+}
+
+var chrome_103 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:      65536,
+		http2.SettingMaxConcurrentStreams: 1000,
+		http2.SettingInitialWindowSize:    6291456,
+		http2.SettingMaxHeaderListSize:    262144,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingMaxConcurrentStreams,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxHeaderListSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":authority",
+		":scheme",
+		":path",
+	},
+	ConnectionFlow: 15663105,
+}
+
+var safari_15_6_1 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingInitialWindowSize:    4194304,
+		http2.SettingMaxConcurrentStreams: 100,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxConcurrentStreams,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":scheme",
+		":path",
+		":authority",
+	},
+	ConnectionFlow: 10485760,
+}
+
+var safari_16_0 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingInitialWindowSize:    4194304,
+		http2.SettingMaxConcurrentStreams: 100,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxConcurrentStreams,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":scheme",
+		":path",
+		":authority",
+	},
+	ConnectionFlow: 10485760,
+}
+
+var safari_Ipad_15_6 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingInitialWindowSize:    2097152,
+		http2.SettingMaxConcurrentStreams: 100,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxConcurrentStreams,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":scheme",
+		":path",
+		":authority",
+	},
+	ConnectionFlow: 10485760,
+}
+
+var safari_IOS_16_0 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingInitialWindowSize:    2097152,
+		http2.SettingMaxConcurrentStreams: 100,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxConcurrentStreams,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":scheme",
+		":path",
+		":authority",
+	},
+	ConnectionFlow: 10485760,
+}
+
+var safari_IOS_15_5 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingInitialWindowSize:    2097152,
+		http2.SettingMaxConcurrentStreams: 100,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxConcurrentStreams,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":scheme",
+		":path",
+		":authority",
+	},
+	ConnectionFlow: 10485760,
+}
+
+var safari_IOS_15_6 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingInitialWindowSize:    2097152,
+		http2.SettingMaxConcurrentStreams: 100,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxConcurrentStreams,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":scheme",
+		":path",
+		":authority",
+	},
+	ConnectionFlow: 10485760,
+}
+
+var firefox_106 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:   65536,
+		http2.SettingInitialWindowSize: 131072,
+		http2.SettingMaxFrameSize:      16384,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxFrameSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":path",
+		":authority",
+		":scheme",
+	},
+	ConnectionFlow: 12517377,
+	Priorities: []http2.Priority{
+		{StreamID: 3, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    200,
+		}},
+		{StreamID: 5, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    100,
+		}},
+		{StreamID: 7, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    0,
+		}},
+		{StreamID: 9, PriorityParam: http2.PriorityParam{
+			StreamDep: 7,
+			Exclusive: false,
+			Weight:    0,
+		}},
+		{StreamID: 11, PriorityParam: http2.PriorityParam{
+			StreamDep: 3,
+			Exclusive: false,
+			Weight:    0,
+		}},
+		{StreamID: 13, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    240,
+		}},
+	},
+}
+
+var firefox_105 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:   65536,
+		http2.SettingInitialWindowSize: 131072,
+		http2.SettingMaxFrameSize:      16384,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxFrameSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":path",
+		":authority",
+		":scheme",
+	},
+	ConnectionFlow: 12517377,
+	Priorities: []http2.Priority{
+		{StreamID: 3, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    200,
+		}},
+		{StreamID: 5, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    100,
+		}},
+		{StreamID: 7, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    0,
+		}},
+		{StreamID: 9, PriorityParam: http2.PriorityParam{
+			StreamDep: 7,
+			Exclusive: false,
+			Weight:    0,
+		}},
+		{StreamID: 11, PriorityParam: http2.PriorityParam{
+			StreamDep: 3,
+			Exclusive: false,
+			Weight:    0,
+		}},
+		{StreamID: 13, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    240,
+		}},
+	},
+}
+
+var firefox_104 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:   65536,
+		http2.SettingInitialWindowSize: 131072,
+		http2.SettingMaxFrameSize:      16384,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxFrameSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":path",
+		":authority",
+		":scheme",
+	},
+	ConnectionFlow: 12517377,
+	Priorities: []http2.Priority{
+		{StreamID: 3, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    200,
+		}},
+		{StreamID: 5, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    100,
+		}},
+		{StreamID: 7, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    0,
+		}},
+		{StreamID: 9, PriorityParam: http2.PriorityParam{
+			StreamDep: 7,
+			Exclusive: false,
+			Weight:    0,
+		}},
+		{StreamID: 11, PriorityParam: http2.PriorityParam{
+			StreamDep: 3,
+			Exclusive: false,
+			Weight:    0,
+		}},
+		{StreamID: 13, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    240,
+		}},
+	},
+}
+
+var firefox_102 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:   65536,
+		http2.SettingInitialWindowSize: 131072,
+		http2.SettingMaxFrameSize:      16384,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxFrameSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":path",
+		":authority",
+		":scheme",
+	},
+	ConnectionFlow: 12517377,
+	Priorities: []http2.Priority{
+		{StreamID: 3, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    200,
+		}},
+		{StreamID: 5, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    100,
+		}},
+		{StreamID: 7, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    0,
+		}},
+		{StreamID: 9, PriorityParam: http2.PriorityParam{
+			StreamDep: 7,
+			Exclusive: false,
+			Weight:    0,
+		}},
+		{StreamID: 11, PriorityParam: http2.PriorityParam{
+			StreamDep: 3,
+			Exclusive: false,
+			Weight:    0,
+		}},
+		{StreamID: 13, PriorityParam: http2.PriorityParam{
+			StreamDep: 0,
+			Exclusive: false,
+			Weight:    240,
+		}},
+	},
+}
+
+var opera_90 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:      65536,
+		http2.SettingMaxConcurrentStreams: 1000,
+		http2.SettingInitialWindowSize:    6291456,
+		http2.SettingMaxHeaderListSize:    262144,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingMaxConcurrentStreams,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxHeaderListSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":authority",
+		":scheme",
+		":path",
+	},
+	ConnectionFlow: 15663105,
+}
+
+var opera_91 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:      65536,
+		http2.SettingMaxConcurrentStreams: 1000,
+		http2.SettingInitialWindowSize:    6291456,
+		http2.SettingMaxHeaderListSize:    262144,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingMaxConcurrentStreams,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxHeaderListSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":authority",
+		":scheme",
+		":path",
+	},
+	ConnectionFlow: 15663105,
+}
+
+var opera_89 = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:      65536,
+		http2.SettingMaxConcurrentStreams: 1000,
+		http2.SettingInitialWindowSize:    6291456,
+		http2.SettingMaxHeaderListSize:    262144,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingMaxConcurrentStreams,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxHeaderListSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":authority",
+		":scheme",
+		":path",
+	},
+	ConnectionFlow: 15663105,
+}
+var zalandoAndroidMobile = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:      4096,
+		http2.SettingMaxConcurrentStreams: math.MaxUint32,
+		http2.SettingInitialWindowSize:    16777216,
+		http2.SettingMaxFrameSize:         16384,
+		http2.SettingMaxHeaderListSize:    math.MaxUint32,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingMaxConcurrentStreams,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxFrameSize,
+		http2.SettingMaxHeaderListSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":path",
+		":authority",
+		":scheme",
+	},
+	ConnectionFlow: 15663105,
+}
+
+var zalandoIosMobile = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:      4096,
+		http2.SettingMaxConcurrentStreams: 100,
+		http2.SettingInitialWindowSize:    2097152,
+		http2.SettingMaxFrameSize:         16384,
+		http2.SettingMaxHeaderListSize:    math.MaxUint32,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingMaxConcurrentStreams,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxFrameSize,
+		http2.SettingMaxHeaderListSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":path",
+		":authority",
+		":scheme",
+	},
+	ConnectionFlow: 15663105,
+}
+
+var nikeIosMobile = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:      4096,
+		http2.SettingMaxConcurrentStreams: 100,
+		http2.SettingInitialWindowSize:    2097152,
+		http2.SettingMaxFrameSize:         16384,
+		http2.SettingMaxHeaderListSize:    math.MaxUint32,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingMaxConcurrentStreams,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxFrameSize,
+		http2.SettingMaxHeaderListSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":scheme",
+		":path",
+		":authority",
+	},
+	ConnectionFlow: 15663105,
+}
+
+var nikeAndroidMobile = ClientProfile{
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:      4096,
+		http2.SettingMaxConcurrentStreams: math.MaxUint32,
+		http2.SettingInitialWindowSize:    16777216,
+		http2.SettingMaxFrameSize:         16384,
+		http2.SettingMaxHeaderListSize:    math.MaxUint32,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingMaxConcurrentStreams,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxFrameSize,
+		http2.SettingMaxHeaderListSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":path",
+		":authority",
+		":scheme",
+	},
+	ConnectionFlow: 15663105,
+}
+
+var cloudflareCustom = ClientProfile{
+	//actually the h2 Settings are not relevant, because this client does only support http1
+	Settings: map[http2.SettingID]uint32{
+		http2.SettingHeaderTableSize:      4096,
+		http2.SettingMaxConcurrentStreams: math.MaxUint32,
+		http2.SettingInitialWindowSize:    16777216,
+		http2.SettingMaxFrameSize:         16384,
+		http2.SettingMaxHeaderListSize:    math.MaxUint32,
+	},
+	SettingsOrder: []http2.SettingID{
+		http2.SettingHeaderTableSize,
+		http2.SettingMaxConcurrentStreams,
+		http2.SettingInitialWindowSize,
+		http2.SettingMaxFrameSize,
+		http2.SettingMaxHeaderListSize,
+	},
+	PseudoHeaderOrder: []string{
+		":method",
+		":path",
+		":authority",
+		":scheme",
+	},
+	ConnectionFlow: 15663105,
+}
+
+// tlsClients maps a tls.ClientHelloID.Str() or a custom name to its HTTP/2 profile
+var tlsClients = map[string]ClientProfile{
+	tls.HelloChrome_103.Str():    chrome_103,
+	tls.HelloChrome_104.Str():    chrome_104,
+	tls.HelloChrome_105.Str():    chrome_105,
+	tls.HelloChrome_106.Str():    chrome_106,
+	tls.HelloSafari_15_6_1.Str(): safari_15_6_1,
+	tls.HelloSafari_16_0.Str():   safari_16_0,
+	tls.HelloIPad_15_6.Str():     safari_Ipad_15_6,
+	tls.HelloIOS_15_5.Str():      safari_IOS_15_5,
+	tls.HelloIOS_15_6.Str():      safari_IOS_15_6,
+	tls.HelloIOS_16_0.Str():      safari_IOS_16_0,
+	tls.HelloFirefox_102.Str():   firefox_102,
+	tls.HelloFirefox_104.Str():   firefox_104,
+	tls.HelloFirefox_105.Str():   firefox_105,
+	tls.HelloFirefox_106.Str():   firefox_106,
+	tls.HelloOpera_89.Str():      opera_89,
+	tls.HelloOpera_90.Str():      opera_90,
+	tls.HelloOpera_91.Str():      opera_91,
+	"zalando_android_mobile":     zalandoAndroidMobile,
+	"zalando_ios_mobile":         zalandoIosMobile,
+	"nike_ios_mobile":            nikeIosMobile,
+	"nike_android_mobile":        nikeAndroidMobile,
+	"cloudflare_custom":          cloudflareCustom,
+}
+
+var tlsClientsMu sync.RWMutex
+
+// http2MaxWindowSize is the largest flow-control window RFC 9113 allows (2^31-1)
+const http2MaxWindowSize = 1<<31 - 1
+
+// http2InitialConnectionWindow is the connection window every HTTP/2 connection starts with
+const http2InitialConnectionWindow = 65535
+
+// It returns a copy of the HTTP/2 profile registered under the given name, changing
+// it does not change the registered profile
+func GetHttp2Profile(name string) (ClientProfile, bool) {
+	tlsClientsMu.RLock()
+	defer tlsClientsMu.RUnlock()
+	profile, exist := tlsClients[name]
+	if !exist {
+		return ClientProfile{}, false
+	}
+	return copyClientProfile(profile), true
+}
+
+// It returns a deep copy of the profile, so the settings, orders and priorities of
+// the copy share no memory with the original
+func copyClientProfile(profile ClientProfile) ClientProfile {
+	result := profile
+	if profile.Settings != nil {
+		result.Settings = make(map[http2.SettingID]uint32, len(profile.Settings))
+		for id, value := range profile.Settings {
+			result.Settings[id] = value
+		}
+	}
+	result.SettingsOrder = append([]http2.SettingID(nil), profile.SettingsOrder...)
+	result.PseudoHeaderOrder = append([]string(nil), profile.PseudoHeaderOrder...)
+	result.Priorities = append([]http2.Priority(nil), profile.Priorities...)
+	return result
+}
+
+// It validates the profile and registers it under the given name, replacing any
+// profile already registered under that name. Use a tls.ClientHelloID.Str() as
+// name to make GetHttp2SettingsfromClient pick the profile for that client.
+func RegisterHttp2Profile(name string, profile ClientProfile) error {
+	if name == "" {
+		return fmt.Errorf("gotools: http2 profile name is empty")
+	}
+	if err := ValidateHttp2Profile(profile); err != nil {
+		return fmt.Errorf("gotools: http2 profile %q: %w", name, err)
+	}
+	tlsClientsMu.Lock()
+	defer tlsClientsMu.Unlock()
+	tlsClients[name] = copyClientProfile(profile)
+	return nil
+}
+
+// Http2ProfileError lists every problem ValidateHttp2Profile found in a profile
+type Http2ProfileError struct {
+	Problems []string
+}
+
+func (e *Http2ProfileError) Error() string {
+	return "invalid http2 profile: " + strings.Join(e.Problems, "; ")
+}
+
+// It checks a profile against the limits of RFC 9113 and returns a *Http2ProfileError
+// listing every problem found, or nil if the profile is usable
+func ValidateHttp2Profile(profile ClientProfile) error {
+	var problems []string
+	var ids []int
+	for id := range profile.Settings {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		setting := http2.SettingID(id)
+		value := profile.Settings[setting]
+		switch setting {
+		case http2.SettingEnablePush:
+			if value > 1 {
+				problems = append(problems, fmt.Sprintf("SETTINGS_ENABLE_PUSH is %d, must be 0 or 1", value))
+			}
+		case http2.SettingInitialWindowSize:
+			if value > http2MaxWindowSize {
+				problems = append(problems, fmt.Sprintf("SETTINGS_INITIAL_WINDOW_SIZE is %d, must not exceed %d", value, http2MaxWindowSize))
+			}
+		case http2.SettingMaxFrameSize:
+			if value < 16384 || value > 16777215 {
+				problems = append(problems, fmt.Sprintf("SETTINGS_MAX_FRAME_SIZE is %d, must be between 16384 and 16777215", value))
+			}
+		}
+	}
+	seensettings := make(map[http2.SettingID]bool)
+	for _, setting := range profile.SettingsOrder {
+		if seensettings[setting] {
+			problems = append(problems, fmt.Sprintf("SettingsOrder lists setting %d more than once", setting))
+			continue
+		}
+		seensettings[setting] = true
+		if _, exist := profile.Settings[setting]; !exist {
+			problems = append(problems, fmt.Sprintf("SettingsOrder lists setting %d which has no value in Settings", setting))
+		}
+	}
+	for _, id := range ids {
+		if !seensettings[http2.SettingID(id)] {
+			problems = append(problems, fmt.Sprintf("setting %d is missing from SettingsOrder", id))
+		}
+	}
+	seenpseudo := make(map[string]bool)
+	for _, pseudo := range profile.PseudoHeaderOrder {
+		name := strings.ReplaceAll(pseudo, " ", "")
+		switch name {
+		case ":method", ":authority", ":scheme", ":path", ":protocol":
+		default:
+			problems = append(problems, fmt.Sprintf("PseudoHeaderOrder contains unknown pseudo header %q", pseudo))
+			continue
+		}
+		if seenpseudo[name] {
+			problems = append(problems, fmt.Sprintf("PseudoHeaderOrder lists %q more than once", name))
+		}
+		seenpseudo[name] = true
+	}
+	if uint64(profile.ConnectionFlow)+http2InitialConnectionWindow > http2MaxWindowSize {
+		problems = append(problems, fmt.Sprintf("ConnectionFlow %d would grow the connection window past %d", profile.ConnectionFlow, http2MaxWindowSize))
+	}
+	seenstreams := make(map[uint32]bool)
+	for _, priority := range profile.Priorities {
+		switch {
+		case priority.StreamID == 0:
+			problems = append(problems, "Priorities contains a PRIORITY frame for stream 0")
+		case priority.StreamID > http2MaxWindowSize:
+			problems = append(problems, fmt.Sprintf("Priorities contains stream %d, stream identifiers are 31 bits", priority.StreamID))
+		case seenstreams[priority.StreamID]:
+			problems = append(problems, fmt.Sprintf("Priorities lists stream %d more than once", priority.StreamID))
+		}
+		seenstreams[priority.StreamID] = true
+		if priority.PriorityParam.StreamDep == priority.StreamID {
+			problems = append(problems, fmt.Sprintf("stream %d depends on itself", priority.StreamID))
+		} else if priority.PriorityParam.StreamDep > http2MaxWindowSize {
+			problems = append(problems, fmt.Sprintf("stream %d depends on stream %d, stream identifiers are 31 bits", priority.StreamID, priority.PriorityParam.StreamDep))
+		}
+	}
+	if len(problems) > 0 {
+		return &Http2ProfileError{Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package gotools
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Charsets for Rand.String
+const (
+	CharsetDigits       = "0123456789"
+	CharsetLower        = "abcdefghijklmnopqrstuvwxyz"
+	CharsetUpper        = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	CharsetLetters      = CharsetLower + CharsetUpper
+	CharsetAlphanumeric = CharsetLetters + CharsetDigits
+	CharsetHex          = "0123456789abcdef"
+	CharsetHexUpper     = "0123456789ABCDEF"
+)
+
+// Rand is a random source that is safe to use from several goroutines. It is not
+// cryptographically secure.
+type Rand struct {
+	mu     sync.Mutex
+	source *rand.Rand
+}
+
+// DefaultRand is the source RandomInt and the package's random helpers use. Seed it
+// to make a run reproducible.
+var DefaultRand = NewRand(time.Now().UnixNano())
+
+// It returns a random source seeded with seed, the same seed gives the same values
+func NewRand(seed int64) *Rand {
+	return &Rand{source: rand.New(rand.NewSource(seed))}
+}
+
+// It reseeds the source
+func (r *Rand) Seed(seed int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.source.Seed(seed)
+}
+
+// It returns a random value in [0, n), n must be above 0
+func (r *Rand) uint64n(n uint64) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n <= math.MaxInt64 {
+		return uint64(r.source.Int63n(int64(n)))
+	}
+	for {
+		if value := r.source.Uint64(); value < n {
+			return value
+		}
+	}
+}
+
+// It returns a random integer between min and max, both included
+func (r *Rand) IntRange(min int, max int) (int, error) {
+	if min > max {
+		return 0, fmt.Errorf("gotools: random range: min %d is greater than max %d", min, max)
+	}
+	span := uint64(max-min) + 1
+	if span == 0 {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return int(r.source.Uint64()), nil
+	}
+	return min + int(r.uint64n(span)), nil
+}
+
+// It returns a random integer from min to max, max excluded
+func (r *Rand) IntRangeExclusive(min int, max int) (int, error) {
+	if min >= max {
+		return 0, fmt.Errorf("gotools: random range: min %d is not less than max %d", min, max)
+	}
+	return min + int(r.uint64n(uint64(max-min))), nil
+}
+
+// It returns a random float in [0, 1)
+func (r *Rand) Float() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.source.Float64()
+}
+
+// It returns a random float in [min, max)
+func (r *Rand) FloatRange(min float64, max float64) (float64, error) {
+	if !(min < max) || math.IsInf(max-min, 0) {
+		return 0, fmt.Errorf("gotools: random range: invalid float range %g to %g", min, max)
+	}
+	return min + r.Float()*(max-min), nil
+}
+
+// It returns a random item of items
+func (r *Rand) Choice(items []string) (string, error) {
+	if len(items) == 0 {
+		return "", fmt.Errorf("gotools: random choice from no items")
+	}
+	return items[r.uint64n(uint64(len(items)))], nil
+}
+
+// It shuffles items in place
+func (r *Rand) Shuffle(items []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.source.Shuffle(len(items), func(i, j int) {
+		items[i], items[j] = items[j], items[i]
+	})
+}
+
+// It returns a random item of items, each picked with a chance proportional to its
+// weight. Weights must not be negative and at least one must be above 0.
+func (r *Rand) WeightedChoice(items []string, weights []float64) (string, error) {
+	if len(items) == 0 {
+		return "", fmt.Errorf("gotools: random choice from no items")
+	}
+	if len(items) != len(weights) {
+		return "", fmt.Errorf("gotools: random choice: %d items but %d weights", len(items), len(weights))
+	}
+	var total float64
+	for i, weight := range weights {
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return "", fmt.Errorf("gotools: random choice: invalid weight %g for %q", weight, items[i])
+		}
+		total += weight
+	}
+	if total == 0 {
+		return "", fmt.Errorf("gotools: random choice: every weight is 0")
+	}
+	target := r.Float() * total
+	for i, weight := range weights {
+		if target < weight {
+			return items[i], nil
+		}
+		target -= weight
+	}
+	for i := len(weights) - 1; i >= 0; i-- {
+		if weights[i] > 0 {
+			return items[i], nil
+		}
+	}
+	return items[len(items)-1], nil
+}
+
+// It returns a random string of length characters picked from charset, like CharsetHex
+func (r *Rand) String(length int, charset string) (string, error) {
+	if length < 0 {
+		return "", fmt.Errorf("gotools: random string: negative length %d", length)
+	}
+	characters := []rune(charset)
+	if len(characters) == 0 {
+		return "", fmt.Errorf("gotools: random string: empty charset")
+	}
+	result := make([]rune, length)
+	for i := range result {
+		result[i] = characters[r.uint64n(uint64(len(characters)))]
+	}
+	return string(result), nil
+}
+
+// It returns a normally distributed float with mean 0 and standard deviation 1
+func (r *Rand) NormFloat() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.source.NormFloat64()
+}
+
+// It returns an exponentially distributed float with mean 1
+func (r *Rand) ExpFloat() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.source.ExpFloat64()
+}
@@ -0,0 +1,130 @@
+package gotools
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	gostruct "github.com/kawacode/gostruct"
+)
+
+// It fills the bot from a raw HTTP request as shown by Burp or a proxy log: method,
+// URL, headers with their exact casing and order, and body. A request target
+// without a scheme is read as https with the Host header as host. The client is
+// picked from the User-Agent with HelloClientFromUserAgent.
+func ParseRawRequest(text string, bot *gostruct.BotData) error {
+	head, body := text, ""
+	crlf, lf := strings.Index(text, "\r\n\r\n"), strings.Index(text, "\n\n")
+	switch {
+	case crlf != -1 && (lf == -1 || crlf < lf):
+		head, body = text[:crlf], text[crlf+4:]
+	case lf != -1:
+		head, body = text[:lf], text[lf+2:]
+	}
+	lines := strings.Split(strings.ReplaceAll(head, "\r\n", "\n"), "\n")
+	requestline := strings.Fields(lines[0])
+	if len(requestline) != 3 || !strings.HasPrefix(requestline[2], "HTTP/") {
+		return fmt.Errorf("gotools: raw request: invalid request line %q", lines[0])
+	}
+	var headers OrderedHeaders
+	for _, line := range lines[1:] {
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(headers) > 0 {
+			headers[len(headers)-1].Value += " " + strings.TrimSpace(line)
+			continue
+		}
+		colon := strings.Index(line, ":")
+		if colon <= 0 {
+			return fmt.Errorf("gotools: raw request: invalid header line %q", line)
+		}
+		headers.Add(line[:colon], strings.TrimSpace(line[colon+1:]))
+	}
+	target := requestline[1]
+	if !strings.Contains(target, "://") {
+		host := headers.Get("Host")
+		if host == "" {
+			return fmt.Errorf("gotools: raw request: no Host header for target %q", target)
+		}
+		target = "https://" + host + target
+	}
+	if _, err := url.Parse(target); err != nil {
+		return fmt.Errorf("gotools: raw request: %w", err)
+	}
+	request := &bot.HttpRequest.Request
+	request.Method = requestline[0]
+	request.URL = target
+	request.Protocol = "1"
+	if strings.HasPrefix(requestline[2], "HTTP/2") {
+		request.Protocol = "2"
+	}
+	if length := headers.Get("Content-Length"); length != "" {
+		if n, err := strconv.Atoi(length); err == nil && n >= 0 && n < len(body) {
+			body = body[:n]
+		}
+	}
+	request.Payload = body
+	headers.SetToBot(bot)
+	if client := HelloClientFromUserAgent(headers.Get("User-Agent")); client != nil {
+		request.Client = *client
+		GetHttp2SettingsfromClient(bot)
+	}
+	return nil
+}
+
+// It returns the bytes the bot's request is sent as over HTTP/1.1: the request line,
+// Host, the headers with the request cookies and Content-Length in the order
+// MapStringToMapStringSlice gives them, and the body. Use it to debug header order
+// problems. The headers come from the bot's header map, so a header repeated in the
+// original request is written once with its values joined, like "X-Dup: 1, 2".
+func SerializeRawRequest(bot *gostruct.BotData) (string, error) {
+	request := bot.HttpRequest.Request
+	parsed, err := url.Parse(request.URL)
+	if err != nil {
+		return "", fmt.Errorf("gotools: raw request: %w", err)
+	}
+	host, err := HostFromURL(request.URL)
+	if err != nil {
+		return "", err
+	}
+	method := request.Method
+	if method == "" {
+		method = "GET"
+	}
+	http1 := *bot
+	http1.HttpRequest.Request.Protocol = "1"
+	headers := make(map[string][]string)
+	for key, value := range request.Headers {
+		if !strings.EqualFold(strings.TrimSpace(key), "Content-Length") {
+			headers[key] = []string{value}
+		}
+	}
+	if _, exist := headerValue(request.Headers, "Cookie"); !exist && len(request.Cookies) > 0 {
+		var cookies []string
+		for _, name := range sortedKeys(request.Cookies) {
+			cookies = append(cookies, name+"="+request.Cookies[name])
+		}
+		headers["Cookie"] = []string{strings.Join(cookies, "; ")}
+	}
+	if request.Payload != "" || method == "POST" || method == "PUT" || method == "PATCH" {
+		headers["Content-Length"] = []string{strconv.Itoa(len(request.Payload))}
+	}
+	var names []string
+	for key := range headers {
+		names = append(names, key)
+	}
+	setHeaderOrderKeys(headers, request.HeaderOrderKey, names, &http1)
+	var raw strings.Builder
+	raw.WriteString(method + " " + parsed.RequestURI() + " HTTP/1.1\r\n")
+	raw.WriteString("Host: " + host + "\r\n")
+	for _, field := range OrderedHeadersFromFhttp(headers) {
+		if !strings.EqualFold(field.Name, "Host") {
+			raw.WriteString(field.Name + ": " + field.Value + "\r\n")
+		}
+	}
+	raw.WriteString("\r\n")
+	raw.WriteString(request.Payload)
+	return raw.String(), nil
+}
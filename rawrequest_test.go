@@ -0,0 +1,114 @@
+package gotools
+
+import (
+	"reflect"
+	"testing"
+
+	gostruct "github.com/kawacode/gostruct"
+)
+
+func TestRawRequestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		url     string
+		payload string
+		want    string
+	}{
+		{
+			name:    "crlf",
+			raw:     "GET /search?q=1 HTTP/1.1\r\nHost: example.com\r\nUser-Agent: test\r\naccept: */*\r\nConnection: keep-alive\r\n\r\n",
+			url:     "https://example.com/search?q=1",
+			payload: "",
+			want:    "GET /search?q=1 HTTP/1.1\r\nHost: example.com\r\nUser-Agent: test\r\naccept: */*\r\nConnection: keep-alive\r\n\r\n",
+		},
+		{
+			name:    "lf only",
+			raw:     "POST /login HTTP/1.1\nHost: example.com\nContent-Type: text/plain\nContent-Length: 3\n\nabc",
+			url:     "https://example.com/login",
+			payload: "abc",
+			want:    "POST /login HTTP/1.1\r\nHost: example.com\r\nContent-Type: text/plain\r\nContent-Length: 3\r\n\r\nabc",
+		},
+		{
+			name:    "folded header",
+			raw:     "GET / HTTP/1.1\r\nHost: example.com\r\nX-Long: one\r\n  two\r\n\tthree\r\nAccept: */*\r\n\r\n",
+			url:     "https://example.com/",
+			payload: "",
+			want:    "GET / HTTP/1.1\r\nHost: example.com\r\nX-Long: one two three\r\nAccept: */*\r\n\r\n",
+		},
+		{
+			name:    "content length truncates",
+			raw:     "PUT /a HTTP/1.1\r\nHost: example.com:8443\r\nContent-Length: 4\r\n\r\nabcdefgh",
+			url:     "https://example.com:8443/a",
+			payload: "abcd",
+			want:    "PUT /a HTTP/1.1\r\nHost: example.com:8443\r\nContent-Length: 4\r\n\r\nabcd",
+		},
+		{
+			name:    "repeated header is joined",
+			raw:     "GET http://example.com/ HTTP/1.1\r\nHost: example.com\r\nX-Dup: 1\r\nX-Dup: 2\r\n\r\n",
+			url:     "http://example.com/",
+			payload: "",
+			want:    "GET / HTTP/1.1\r\nHost: example.com\r\nX-Dup: 1, 2\r\n\r\n",
+		},
+	}
+	for _, test := range tests {
+		var bot gostruct.BotData
+		if err := ParseRawRequest(test.raw, &bot); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if bot.HttpRequest.Request.URL != test.url || bot.HttpRequest.Request.Payload != test.payload {
+			t.Errorf("%s: parsed %s %q, want %s %q", test.name, bot.HttpRequest.Request.URL, bot.HttpRequest.Request.Payload, test.url, test.payload)
+		}
+		raw, err := SerializeRawRequest(&bot)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if raw != test.want {
+			t.Errorf("%s: serialized\n%q\nwant\n%q", test.name, raw, test.want)
+		}
+		var again gostruct.BotData
+		if err := ParseRawRequest(raw, &again); err != nil {
+			t.Errorf("%s: parsing the serialized request: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(again.HttpRequest.Request.Headers, bot.HttpRequest.Request.Headers) ||
+			!reflect.DeepEqual(again.HttpRequest.Request.HeaderOrderKey, bot.HttpRequest.Request.HeaderOrderKey) {
+			t.Errorf("%s: round trip headers %v %v, want %v %v", test.name, again.HttpRequest.Request.Headers,
+				again.HttpRequest.Request.HeaderOrderKey, bot.HttpRequest.Request.Headers, bot.HttpRequest.Request.HeaderOrderKey)
+		}
+	}
+}
+
+func TestSerializeRawRequestCookies(t *testing.T) {
+	var bot gostruct.BotData
+	request := &bot.HttpRequest.Request
+	request.URL = "http://example.com/x"
+	request.Method = "POST"
+	request.Payload = "a=1"
+	request.Headers = map[string]string{"User-Agent": "test", "Connection": "keep-alive", "Content-Length": "99"}
+	request.Cookies = map[string]string{"b": "2", "a": "1"}
+	request.HeaderOrderKey = []string{"User-Agent", "Cookie", "Content-Length", "Connection"}
+	raw, err := SerializeRawRequest(&bot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "POST /x HTTP/1.1\r\nHost: example.com\r\nUser-Agent: test\r\nCookie: a=1; b=2\r\nContent-Length: 3\r\nConnection: keep-alive\r\n\r\na=1"
+	if raw != want {
+		t.Errorf("serialized\n%q\nwant\n%q", raw, want)
+	}
+}
+
+func TestParseRawRequestErrors(t *testing.T) {
+	for _, raw := range []string{
+		"GET /\r\n\r\n",
+		"GET / HTTP/1.1\r\nno colon\r\n\r\n",
+		"GET / HTTP/1.1\r\nAccept: */*\r\n\r\n",
+	} {
+		var bot gostruct.BotData
+		if err := ParseRawRequest(raw, &bot); err == nil {
+			t.Errorf("ParseRawRequest(%q) returned no error", raw)
+		}
+	}
+}
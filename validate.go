@@ -0,0 +1,162 @@
+package gotools
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// IntOptions set what IsIntWith accepts
+type IntOptions struct {
+	// AllowSign accepts a leading + or -
+	AllowSign bool
+	// BitSize rejects numbers that do not fit an integer of that many bits, signed
+	// if AllowSign is set. 0 accepts any number of digits.
+	BitSize int
+}
+
+// It returns true if the string is a non empty run of digits
+func IsInt(s string) bool {
+	return IsIntWith(s, IntOptions{})
+}
+
+// It returns true if the string is an integer as allowed by the options
+func IsIntWith(s string, options IntOptions) bool {
+	digits := s
+	if options.AllowSign && (strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-")) {
+		digits = s[1:]
+	}
+	if digits == "" {
+		return false
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	if options.BitSize == 0 {
+		return true
+	}
+	var err error
+	if options.AllowSign {
+		_, err = strconv.ParseInt(s, 10, options.BitSize)
+	} else {
+		_, err = strconv.ParseUint(s, 10, options.BitSize)
+	}
+	return err == nil
+}
+
+// It returns true if the string is a finite decimal number like "-1.5" or "2e10"
+func IsFloat(s string) bool {
+	value, err := strconv.ParseFloat(s, 64)
+	return err == nil && !math.IsInf(value, 0) && !math.IsNaN(value) && !strings.ContainsAny(s, "xXpP_")
+}
+
+// It returns true if the string is a non empty run of hex digits
+func IsHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// It returns true if the string is non empty standard or URL-safe base64, padded or not
+func IsBase64(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if _, err := encoding.DecodeString(s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// It returns true if the string is a bare email address like "bob@example.com",
+// without a display name
+func IsEmail(s string) bool {
+	address, err := mail.ParseAddress(s)
+	if err != nil || address.Address != s {
+		return false
+	}
+	domain := s[strings.LastIndex(s, "@")+1:]
+	return strings.Contains(domain, ".") && !strings.HasPrefix(domain, ".") && !strings.HasSuffix(domain, ".")
+}
+
+// It returns true if the string is an absolute URL with a scheme and a host
+func IsURL(s string) bool {
+	parsed, err := url.Parse(s)
+	return err == nil && parsed.Scheme != "" && parsed.Host != "" && !strings.ContainsAny(s, " \t\r\n")
+}
+
+// It returns true if the string is valid JSON
+func IsJSON(s string) bool {
+	return json.Valid([]byte(s))
+}
+
+// It parses an unsigned decimal integer that fits in bitsize bits. Unlike fmt.Sscan
+// it rejects signs, spaces and trailing characters.
+func ParseUint(s string, bitsize int) (uint64, error) {
+	if !IsInt(s) {
+		return 0, fmt.Errorf("gotools: %q is not an unsigned integer", s)
+	}
+	value, err := strconv.ParseUint(s, 10, bitsize)
+	if err != nil {
+		return 0, fmt.Errorf("gotools: %q does not fit in %d bits", s, bitsize)
+	}
+	return value, nil
+}
+
+// It parses a list of unsigned integers separated by sep, like the "4865-4866-4867"
+// fields of a JA3. An empty string is an empty list.
+func ParseUintList(s string, sep string, bitsize int) ([]uint64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var result []uint64
+	for _, item := range strings.Split(s, sep) {
+		value, err := ParseUint(item, bitsize)
+		if err != nil {
+			return nil, fmt.Errorf("gotools: list %q: %w", s, err)
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+// It parses a list of 16-bit unsigned integers separated by sep, see ParseUintList
+func ParseUint16List(s string, sep string) ([]uint16, error) {
+	values, err := ParseUintList(s, sep, 16)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]uint16, len(values))
+	for i, value := range values {
+		result[i] = uint16(value)
+	}
+	return result, nil
+}
+
+// It parses a list of 8-bit unsigned integers separated by sep, see ParseUintList
+func ParseUint8List(s string, sep string) ([]uint8, error) {
+	values, err := ParseUintList(s, sep, 8)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]uint8, len(values))
+	for i, value := range values {
+		result[i] = uint8(value)
+	}
+	return result, nil
+}
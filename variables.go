@@ -0,0 +1,232 @@
+package gotools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	gostruct "github.com/kawacode/gostruct"
+)
+
+// Variables are the values <name> placeholders are replaced with. A variable is a
+// string, a list indexed like <name[0]> or a dictionary keyed like <name[key]>.
+type Variables struct {
+	mu     sync.RWMutex
+	values map[string]interface{}
+}
+
+// UndefinedVariableError lists the placeholders Interpolate found no value for
+type UndefinedVariableError struct {
+	Names []string
+}
+
+func (e *UndefinedVariableError) Error() string {
+	return "gotools: undefined variables: " + strings.Join(e.Names, ", ")
+}
+
+// It returns an empty variable store
+func NewVariables() *Variables {
+	return &Variables{values: make(map[string]interface{})}
+}
+
+// It sets a string variable
+func (v *Variables) Set(name string, value string) {
+	v.set(name, value)
+}
+
+// It sets a list variable, indexed like <name[0]> or <name[-1]> for the last item
+func (v *Variables) SetList(name string, value []string) {
+	v.set(name, append([]string(nil), value...))
+}
+
+// It sets a dictionary variable, keyed like <name[key]>
+func (v *Variables) SetDict(name string, value map[string]string) {
+	dict := make(map[string]string, len(value))
+	for k, item := range value {
+		dict[k] = item
+	}
+	v.set(name, dict)
+}
+
+func (v *Variables) set(name string, value interface{}) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.values[name] = value
+}
+
+// It removes a variable
+func (v *Variables) Delete(name string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.values, name)
+}
+
+// It returns the value of a variable: a string, a []string or a map[string]string
+func (v *Variables) Get(name string) (interface{}, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	value, exist := v.values[name]
+	return value, exist
+}
+
+// It returns the string a placeholder like name, name[0] or name[key] stands for.
+// The bool is false if the variable is not set.
+func (v *Variables) resolve(placeholder string) (string, bool, error) {
+	name, index, indexed := placeholder, "", false
+	if open := strings.IndexByte(placeholder, '['); open != -1 {
+		name, index, indexed = placeholder[:open], placeholder[open+1:len(placeholder)-1], true
+	}
+	value, exist := v.Get(name)
+	if !exist {
+		return "", false, nil
+	}
+	switch value := value.(type) {
+	case string:
+		if indexed {
+			return "", true, fmt.Errorf("gotools: variable %q is a string, it cannot be indexed", name)
+		}
+		return value, true, nil
+	case []string:
+		if !indexed {
+			return "", true, fmt.Errorf("gotools: variable %q is a list, index it like <%s[0]>", name, name)
+		}
+		i, err := strconv.Atoi(index)
+		if err != nil {
+			return "", true, fmt.Errorf("gotools: variable %q is a list, %q is not an index", name, index)
+		}
+		if i < 0 {
+			i += len(value)
+		}
+		if i < 0 || i >= len(value) {
+			return "", true, fmt.Errorf("gotools: variable %q has %d items, index %s is out of range", name, len(value), index)
+		}
+		return value[i], true, nil
+	case map[string]string:
+		if !indexed {
+			return "", true, fmt.Errorf("gotools: variable %q is a dictionary, key it like <%s[key]>", name, name)
+		}
+		item, exist := value[index]
+		if !exist {
+			return "", false, nil
+		}
+		return item, true, nil
+	}
+	return "", true, fmt.Errorf("gotools: variable %q has unsupported type %T", name, value)
+}
+
+// It reports whether the text between < and > is a placeholder: a name of letters,
+// digits, "_", "-" and "." optionally followed by one [index or key]
+func isPlaceholder(text string) bool {
+	name := text
+	if open := strings.IndexByte(text, '['); open != -1 {
+		if !strings.HasSuffix(text, "]") || open == len(text)-2 {
+			return false
+		}
+		name = text[:open]
+	}
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// It replaces every <name>, <list[0]> and <dict[key]> placeholder in text with its
+// value. \< and \> are a literal < and >, and \\ a literal backslash. A < that
+// does not start a placeholder, like in "a < b", is kept. It returns an
+// *UndefinedVariableError listing every placeholder without a value.
+func (v *Variables) Interpolate(text string) (string, error) {
+	var (
+		result    strings.Builder
+		undefined []string
+	)
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == '\\' && i+1 < len(text) && (text[i+1] == '<' || text[i+1] == '>' || text[i+1] == '\\') {
+			result.WriteByte(text[i+1])
+			i++
+			continue
+		}
+		if c == '<' {
+			if end := strings.IndexByte(text[i+1:], '>'); end != -1 && isPlaceholder(text[i+1:i+1+end]) {
+				placeholder := text[i+1 : i+1+end]
+				value, exist, err := v.resolve(placeholder)
+				if err != nil {
+					return text, err
+				}
+				if !exist {
+					undefined = append(undefined, placeholder)
+				}
+				result.WriteString(value)
+				i += end + 1
+				continue
+			}
+		}
+		result.WriteByte(c)
+	}
+	if len(undefined) > 0 {
+		return text, &UndefinedVariableError{Names: undefined}
+	}
+	return result.String(), nil
+}
+
+// It interpolates the bot's URL, header names and values, cookie names and values
+// and payload with the variables, see Variables.Interpolate. Nil variables are an
+// empty store. Call it before sending. On error the bot is left unchanged.
+func InterpolateBot(bot *gostruct.BotData, variables *Variables) error {
+	if variables == nil {
+		variables = NewVariables()
+	}
+	request := &bot.HttpRequest.Request
+	url, err := variables.Interpolate(request.URL)
+	if err != nil {
+		return fmt.Errorf("gotools: interpolating url: %w", err)
+	}
+	payload, err := variables.Interpolate(request.Payload)
+	if err != nil {
+		return fmt.Errorf("gotools: interpolating payload: %w", err)
+	}
+	headers, err := interpolateMap(variables, request.Headers)
+	if err != nil {
+		return fmt.Errorf("gotools: interpolating headers: %w", err)
+	}
+	cookies, err := interpolateMap(variables, request.Cookies)
+	if err != nil {
+		return fmt.Errorf("gotools: interpolating cookies: %w", err)
+	}
+	order := make([]string, len(request.HeaderOrderKey))
+	for i, name := range request.HeaderOrderKey {
+		if order[i], err = variables.Interpolate(name); err != nil {
+			return fmt.Errorf("gotools: interpolating header order: %w", err)
+		}
+	}
+	request.URL, request.Payload = url, payload
+	request.Headers, request.Cookies = headers, cookies
+	if request.HeaderOrderKey != nil {
+		request.HeaderOrderKey = order
+	}
+	return nil
+}
+
+// It interpolates the keys and values of a map into a new map
+func interpolateMap(variables *Variables, values map[string]string) (map[string]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+	result := make(map[string]string, len(values))
+	for k, value := range values {
+		key, err := variables.Interpolate(k)
+		if err != nil {
+			return nil, err
+		}
+		if result[key], err = variables.Interpolate(value); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}